@@ -0,0 +1,33 @@
+package shecomp
+
+import "bytes"
+
+// IsLikelyPadded reports whether the raw data ends with a valid SHE padding of its own message,
+// that is, the data is multiple of the block size and its tail is a single 1 bit, 0 bits and the 40 bits length field
+// which agrees with the length of the data before the padding.
+// A raw message may satisfy it by chance, so it is a heuristic.
+func IsLikelyPadded(data []byte) bool {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return false
+	}
+	l := data[len(data)-5:]
+	bits := uint64(l[0])<<32 | uint64(l[1])<<24 | uint64(l[2])<<16 | uint64(l[3])<<8 | uint64(l[4])
+	if bits%8 != 0 || bits/8 >= uint64(len(data)) {
+		return false
+	}
+	n := int(bits / 8)
+	if n+padLen(n%blockSize) != len(data) {
+		return false
+	}
+	pad := make([]byte, len(data)-n)
+	putPadding(pad, uint64(n))
+	return bytes.Equal(data[n:], pad)
+}
+
+// AnalyzeInput tells how the raw data would be compressed:
+// looksPadded is the result of IsLikelyPadded, suggesting to compress it without padding,
+// blocks is the number of complete blocks in the data, and trailingBytes is the length of the incomplete last block.
+// The data can be compressed without padding only if trailingBytes is 0.
+func AnalyzeInput(data []byte) (looksPadded bool, blocks int, trailingBytes int) {
+	return IsLikelyPadded(data), len(data) / blockSize, len(data) % blockSize
+}
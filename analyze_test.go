@@ -0,0 +1,38 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestAnalyzeInput(t *testing.T) {
+	decode := func(s string) []byte {
+		b, _ := hex.DecodeString(s)
+		return b
+	}
+	msg := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+
+	tests := []struct {
+		name     string
+		data     []byte
+		padded   bool
+		blocks   int
+		trailing int
+	}{
+		{"padded message", decode(msg + "80000000000000000000000000000100"), true, 3, 0},
+		{"padding spans the last block", decode(msg[:54] + "80" + strings.Repeat("00", 15) + "00000000d8"), true, 3, 0},
+		{"unpadded message", decode(msg), false, 2, 0},
+		{"incomplete block", decode(msg[:40]), false, 1, 4},
+		{"wrong length field", decode(msg + "80000000000000000000000000000108"), false, 3, 0},
+		{"empty", nil, false, 0, 0},
+	}
+	for _, tt := range tests {
+		padded, blocks, trailing := shecomp.AnalyzeInput(tt.data)
+		if padded != tt.padded || blocks != tt.blocks || trailing != tt.trailing {
+			t.Errorf("%s: AnalyzeInput() = (%v, %d, %d), want (%v, %d, %d)", tt.name, padded, blocks, trailing, tt.padded, tt.blocks, tt.trailing)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package shecomp
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Scratch holds all the buffers which CompressArena needs.
+// The zero value is ready to use. A Scratch must not be used by multiple goroutines at the same time.
+type Scratch struct {
+	state [blockSize]byte
+	block [blockSize]byte
+	tmp   [blockSize]byte
+	last  [2 * blockSize]byte // the message tail followed by the padding
+	rk    [11 * blockSize]byte
+}
+
+// CompressArena compresses the hexadecimal encoded data with padding same as Compress,
+// and writes the hexadecimal encoded digest into dst.
+// Same as Compress, it returns ErrInvalidHex wrapping the error of hex.Decode if data is not well-formed.
+// All the intermediate values are kept in scratch, and scratch is cleared before returning.
+// Since the key schedule is kept in scratch too, CompressArena does not allocate at all.
+// It uses a constant-time AES implementation instead of crypto/aes for that, which is much slower,
+// so prefer Compress unless the allocation matters.
+func CompressArena(dst *[2 * blockSize]byte, scratch *Scratch, data []byte) error {
+	if len(data)%2 != 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidHex, hex.ErrLength)
+	}
+	n := len(data) / 2
+	if uint64(n)*8 > maxBitLength {
+		return ErrLargePlainText
+	}
+
+	s := scratch
+	defer s.reset()
+	s.state = [blockSize]byte{}
+	full := n - n%blockSize
+	for i := 0; i < full; i += blockSize {
+		if _, err := hex.Decode(s.block[:], data[2*i:2*(i+blockSize)]); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidHex, err)
+		}
+		s.step()
+	}
+
+	tail := n - full
+	if _, err := hex.Decode(s.last[:tail], data[2*full:]); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidHex, err)
+	}
+	end := tail + padLen(tail)
+	putPadding(s.last[tail:end], uint64(n))
+	for i := 0; i < end; i += blockSize {
+		copy(s.block[:], s.last[i:i+blockSize])
+		s.step()
+	}
+
+	hex.Encode(dst[:], s.state[:])
+	return nil
+}
+
+// step compresses s.block into s.state.
+func (s *Scratch) step() {
+	expandKey(&s.rk, &s.state)
+	encryptBlock(&s.rk, &s.tmp, &s.block)
+	for i := range s.state {
+		s.state[i] ^= s.tmp[i] ^ s.block[i]
+	}
+}
+
+// reset clears the chaining value and the message bytes left in s.
+func (s *Scratch) reset() {
+	*s = Scratch{}
+}
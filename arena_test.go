@@ -0,0 +1,51 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressArena(t *testing.T) {
+	var dst [32]byte
+	var scratch shecomp.Scratch
+
+	// cover the tails which need one and two blocks of the padding
+	msg := strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 2)
+	for n := 0; n <= len(msg)/2; n++ {
+		data := []byte(msg[:2*n])
+		if err := shecomp.CompressArena(&dst, &scratch, data); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		want, _ := shecomp.Compress(strings.NewReader(string(data)))
+		if string(dst[:]) != string(want) {
+			t.Errorf("%d bytes: CompressArena() = %s, want %s", n, dst, want)
+		}
+		if scratch != (shecomp.Scratch{}) {
+			t.Errorf("%d bytes: scratch is not cleared", n)
+		}
+	}
+
+	if err := shecomp.CompressArena(&dst, &scratch, []byte("0")); !errors.Is(err, hex.ErrLength) {
+		t.Errorf("expected hex.ErrLength, got %v", err)
+	}
+}
+
+func TestCompressArenaAllocs(t *testing.T) {
+	var dst [32]byte
+	var scratch shecomp.Scratch
+	data := []byte("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := shecomp.CompressArena(&dst, &scratch, data); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("CompressArena allocates %v times, want 0", allocs)
+	}
+}
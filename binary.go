@@ -0,0 +1,28 @@
+package shecomp
+
+import (
+	"fmt"
+	"io"
+)
+
+// CompressBinary is same as Compress, but reads the raw binary (not hexadecimal encoded) input.
+// The padding is calculated from the length of the raw input, so it returns the same digest
+// as Compress over the hexadecimal encoding of the input.
+// The output is still encoded in hexadecimal.
+func CompressBinary(r io.Reader) ([]byte, error) {
+	return NewCompressor(WithBinaryInput()).Compress(r)
+}
+
+// CompressBinaryToHex compresses the raw binary (not hexadecimal encoded) input with padding,
+// and writes the hexadecimal encoded digest to w.
+// Same as Compress, it returns ErrLargePlainText if the input is longer than 1<<40 - 1 in bit.
+func CompressBinaryToHex(w io.Writer, r io.Reader) error {
+	d, err := CompressBinary(r)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(d); err != nil {
+		return fmt.Errorf("failed to write the digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressBinary(t *testing.T) {
+	for _, s := range []string{
+		"",
+		strings.Repeat("88", 10),
+		strings.Repeat("88", 11),
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+	} {
+		raw, _ := hex.DecodeString(s)
+		want, _ := shecomp.Compress(strings.NewReader(s))
+		for _, r := range []func() io.Reader{
+			func() io.Reader { return bytes.NewReader(raw) },
+			func() io.Reader { return iotest.OneByteReader(bytes.NewReader(raw)) },
+		} {
+			got, err := shecomp.CompressBinary(r())
+			if err != nil {
+				t.Errorf("%d bytes: unexpected error: %v", len(raw), err)
+				continue
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("%d bytes: CompressBinary() = %s, want %s", len(raw), got, want)
+			}
+		}
+	}
+}
+
+func TestCompressBinaryToHex(t *testing.T) {
+	raw, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	want := "c7277a0dc1fb853b5f4d9cbd26be40c6"
+
+	var b bytes.Buffer
+	if err := shecomp.CompressBinaryToHex(&b, bytes.NewReader(raw)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if b.String() != want {
+		t.Errorf("got %s, want %s", b.String(), want)
+	}
+}
@@ -18,10 +18,35 @@ func TestErrLargePlainText(t *testing.T) {
 
 	o := make([]byte, blockSize)
 
-	if err := br.block(o); err != nil {
+	if err := br.Block(o); err != nil {
 		t.Errorf("Expected nil, got %v", err)
 	}
-	if err := br.block(o); !errors.Is(err, ErrLargePlainText) {
+	if err := br.Block(o); !errors.Is(err, ErrLargePlainText) {
 		t.Errorf("Expected ErrLargePlainText, got %v", err)
 	}
 }
+
+func TestErrLargePlainTextExtended(t *testing.T) {
+	// The same boundary check as TestErrLargePlainText with the 64 bits length field.
+	s := strings.Repeat("00", 2*blockSize)
+	br := newPaddingReader(strings.NewReader(s))
+	br.scheme = PaddingExtendedLength
+	br.readBytes = maxExtendedBitLength/8 - blockSize
+
+	o := make([]byte, blockSize)
+
+	if err := br.Block(o); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	if err := br.Block(o); !errors.Is(err, ErrLargePlainText) {
+		t.Errorf("Expected ErrLargePlainText, got %v", err)
+	}
+
+	// the message beyond the 40 bits length field is accepted
+	br = newPaddingReader(strings.NewReader(s))
+	br.scheme = PaddingExtendedLength
+	br.readBytes = maxBitLength / 8
+	if err := br.Block(o); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
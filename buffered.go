@@ -0,0 +1,14 @@
+package shecomp
+
+import (
+	"bufio"
+	"io"
+)
+
+// CompressBuffered is same as Compress, but reads r through a bufio.Reader of bufSize bytes.
+// Compress reads r by 32 bytes, the hexadecimal text of a block, so a source with an overhead per Read,
+// such as an unbuffered file or socket, is read in fewer calls. The input is still processed in a streaming manner.
+// 4096 bytes or more is recommended; bufSize less than 16 is raised to the minimum size of bufio.
+func CompressBuffered(r io.Reader, bufSize int) ([]byte, error) {
+	return Compress(bufio.NewReaderSize(r, bufSize))
+}
@@ -0,0 +1,51 @@
+package shecomp_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressBuffered(t *testing.T) {
+	s := strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 100)
+	want, _ := shecomp.Compress(strings.NewReader(s))
+	for _, size := range []int{0, 16, 4096} {
+		got, err := shecomp.CompressBuffered(strings.NewReader(s), size)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("CompressBuffered(%d) = %s, want %s", size, got, want)
+		}
+	}
+}
+
+// syscallReader simulates a source with a fixed overhead per Read, such as a system call.
+type syscallReader struct {
+	r io.Reader
+}
+
+func (r *syscallReader) Read(p []byte) (int, error) {
+	for start := time.Now(); time.Since(start) < time.Microsecond; {
+	}
+	return r.r.Read(p)
+}
+
+func BenchmarkCompressBuffered(b *testing.B) {
+	s := strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 1<<10)
+	b.Run("unbuffered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			shecomp.Compress(&syscallReader{strings.NewReader(s)})
+		}
+	})
+	b.Run("buffered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			shecomp.CompressBuffered(&syscallReader{strings.NewReader(s)}, 4096)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package shecomp
+
+import "io"
+
+// DigestBuilder concatenates the raw digests of several inputs.
+// The zero value is ready to use.
+type DigestBuilder struct {
+	buf []byte
+}
+
+// Add compresses the hexadecimal input same as CompressRaw, and appends the digest.
+// On error, nothing is appended.
+func (b *DigestBuilder) Add(r io.Reader) error {
+	d, err := CompressRaw(r)
+	if err != nil {
+		return err
+	}
+	b.buf = append(b.buf, d...)
+	return nil
+}
+
+// Bytes returns the concatenation of the digests in the order they were added.
+// The slice aliases the internal buffer, and is valid until the next Add.
+func (b *DigestBuilder) Bytes() []byte {
+	return b.buf
+}
+
+// Len returns the number of bytes accumulated, which is 16 times the number of inputs added.
+func (b *DigestBuilder) Len() int {
+	return len(b.buf)
+}
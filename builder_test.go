@@ -0,0 +1,37 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestDigestBuilder(t *testing.T) {
+	inputs := []string{"", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", strings.Repeat("88", 26)}
+
+	var b shecomp.DigestBuilder
+	var want []byte
+	for _, s := range inputs {
+		if err := b.Add(strings.NewReader(s)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		d, _ := shecomp.CompressRaw(strings.NewReader(s))
+		want = append(want, d...)
+	}
+	if b.Len() != 48 {
+		t.Errorf("Len() = %d, want 48", b.Len())
+	}
+	if !bytes.Equal(want, b.Bytes()) {
+		t.Errorf("Bytes() = %x, want %x", b.Bytes(), want)
+	}
+
+	if err := b.Add(strings.NewReader("012")); err == nil {
+		t.Error("expected error for invalid hexadecimal input")
+	}
+	if b.Len() != 48 {
+		t.Errorf("Len() = %d after a failed Add, want 48", b.Len())
+	}
+}
@@ -0,0 +1,26 @@
+package shecomp
+
+import (
+	"fmt"
+	"io"
+)
+
+// CompressBundle compresses each segment independently, and also the concatenation of all segments in the same pass.
+// Each segment must be hexadecimal encoded and is read only once in order.
+// perSegment[i] is same as Compress(segments[i]), and combined is same as Compress over all segments concatenated.
+// An empty segment has the digest of the empty message and contributes nothing to combined.
+// The outputs are encoded in hexadecimal.
+func CompressBundle(segments []io.Reader) (perSegment [][]byte, combined []byte, err error) {
+	var whole Compressor
+	perSegment = make([][]byte, 0, len(segments))
+	for i, s := range segments {
+		br := newPaddingReader(s)
+		br.tee = &whole
+		d, err := NewCompressor().run(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compress segment %d: %w", i, err)
+		}
+		perSegment = append(perSegment, d)
+	}
+	return perSegment, encodeHex(whole.Sum(nil)), nil
+}
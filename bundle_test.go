@@ -0,0 +1,59 @@
+package shecomp_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressBundle(t *testing.T) {
+	segments := []string{
+		"6bc1bee22e409f96e93d7e117393172a",
+		"",
+		"ae2d8a571e03ac9c9eb76fac45af8e5101",
+	}
+	readers := make([]io.Reader, len(segments))
+	for i, s := range segments {
+		readers[i] = strings.NewReader(s)
+	}
+
+	perSegment, combined, err := shecomp.CompressBundle(readers)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if len(perSegment) != len(segments) {
+		t.Errorf("got %d digests, want %d", len(perSegment), len(segments))
+		return
+	}
+	for i, s := range segments {
+		want, err := shecomp.Compress(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(want, perSegment[i]) {
+			t.Errorf("segment %d: got %s, want %s", i, perSegment[i], want)
+		}
+	}
+
+	want, err := shecomp.Compress(strings.NewReader(strings.Join(segments, "")))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(want, combined) {
+		t.Errorf("combined = %s, want %s", combined, want)
+	}
+}
+
+func TestCompressBundleInvalidSegment(t *testing.T) {
+	readers := []io.Reader{strings.NewReader("00"), strings.NewReader("0g")}
+	if _, _, err := shecomp.CompressBundle(readers); err == nil {
+		t.Error("expected error for invalid segment")
+	}
+}
@@ -0,0 +1,18 @@
+package shecomp
+
+import "bytes"
+
+// CompressBytes is same as Compress, but takes the hexadecimal encoded input as a slice.
+func CompressBytes(src []byte) ([]byte, error) {
+	return Compress(bytes.NewReader(src))
+}
+
+// PaddingBytes is same as Padding, but takes the hexadecimal encoded input as a slice.
+func PaddingBytes(src []byte) ([]byte, error) {
+	return Padding(bytes.NewReader(src))
+}
+
+// CompressWithoutPaddingBytes is same as CompressWithoutPadding, but takes the hexadecimal encoded input as a slice.
+func CompressWithoutPaddingBytes(src []byte) ([]byte, error) {
+	return CompressWithoutPadding(bytes.NewReader(src))
+}
@@ -0,0 +1,48 @@
+package shecomp_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestBytesWrappers(t *testing.T) {
+	funcs := []struct {
+		name   string
+		bytes  func([]byte) ([]byte, error)
+		reader func(io.Reader) ([]byte, error)
+	}{
+		{"CompressBytes", shecomp.CompressBytes, shecomp.Compress},
+		{"PaddingBytes", shecomp.PaddingBytes, shecomp.Padding},
+		{"CompressWithoutPaddingBytes", shecomp.CompressWithoutPaddingBytes, shecomp.CompressWithoutPadding},
+	}
+	inputs := []string{
+		"",
+		strings.Repeat("88", 26),
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+		"000102030405060708090a0b0c0d0e0f010153484500800000000000000000b0",
+		"012",
+	}
+
+	for _, f := range funcs {
+		for _, s := range inputs {
+			want, wantErr := f.reader(strings.NewReader(s))
+			got, err := f.bytes([]byte(s))
+			if (err == nil) != (wantErr == nil) {
+				t.Errorf("%s(%q): error = %v, want %v", f.name, s, err, wantErr)
+				continue
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("%s(%q) = %s, want %s", f.name, s, got, want)
+			}
+		}
+	}
+
+	if _, err := shecomp.CompressWithoutPaddingBytes([]byte(strings.Repeat("0", 30))); !errors.Is(err, shecomp.ErrNeedPadding) {
+		t.Errorf("expected ErrNeedPadding, got %v", err)
+	}
+}
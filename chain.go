@@ -0,0 +1,35 @@
+package shecomp
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+// ChainDigest folds the entries of an append-only log into a hash chain using SHE compression.
+// Starting from h0 = 16 bytes of zero, each entry is folded as h(i) = Compress(h(i-1) || entries[i-1]),
+// where Compress includes the SHE padding over the 16 + len(entries[i-1]) bytes.
+// It returns the last value h(n), or h0 if there is no entry.
+// The entries and the output are raw bytes, not hexadecimal encoded.
+func ChainDigest(entries [][]byte) ([]byte, error) {
+	h := make([]byte, blockSize)
+	var c Compressor
+	for i, e := range entries {
+		c.Reset()
+		c.Write(h)
+		if _, err := c.Write(e); err != nil {
+			return nil, fmt.Errorf("failed to fold entry %d: %w", i, err)
+		}
+		h = c.Sum(h[:0])
+	}
+	return h, nil
+}
+
+// VerifyChain reports whether folding the entries by ChainDigest results in finalDigest.
+// The comparison is done in constant time.
+func VerifyChain(entries [][]byte, finalDigest []byte) (bool, error) {
+	h, err := ChainDigest(entries)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(h, finalDigest) == 1, nil
+}
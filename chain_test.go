@@ -0,0 +1,47 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestVerifyChain(t *testing.T) {
+	entries := [][]byte{
+		[]byte("boot"),
+		[]byte("key update: slot 4"),
+		{},
+		[]byte("shutdown"),
+	}
+
+	final, err := shecomp.ChainDigest(entries)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	// the first link must be Compress(0^128 || entries[0])
+	first, err := shecomp.ChainDigest(entries[:1])
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want, _ := shecomp.Compress(strings.NewReader(hex.EncodeToString(append(make([]byte, 16), entries[0]...))))
+	if hex.EncodeToString(first) != string(want) {
+		t.Errorf("first link = %x, want %s", first, want)
+	}
+
+	ok, err := shecomp.VerifyChain(entries, final)
+	if err != nil || !ok {
+		t.Errorf("valid chain: got (%v, %v), want (true, nil)", ok, err)
+	}
+
+	tampered := append([][]byte{}, entries...)
+	tampered[1] = []byte("key update: slot 5")
+	ok, err = shecomp.VerifyChain(tampered, final)
+	if err != nil || ok {
+		t.Errorf("tampered chain: got (%v, %v), want (false, nil)", ok, err)
+	}
+}
@@ -0,0 +1,20 @@
+package shecomp
+
+import (
+	"hash/crc32"
+	"io"
+)
+
+// CompressWithCRC32 compresses the input data same as Compress,
+// and computes the CRC-32 (IEEE) checksum of the input data in the same pass.
+// The checksum is calculated over the decoded message bytes, not over the hexadecimal text nor the padding.
+func CompressWithCRC32(r io.Reader) ([]byte, uint32, error) {
+	crc := crc32.NewIEEE()
+	br := newPaddingReader(r)
+	br.tee = crc
+	digest, err := NewCompressor().run(br)
+	if err != nil {
+		return nil, 0, err
+	}
+	return digest, crc.Sum32(), nil
+}
@@ -0,0 +1,41 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"hash/crc32"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressWithCRC32(t *testing.T) {
+	inputs := []string{
+		"",
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+		strings.Repeat("88", 27),
+	}
+
+	for _, s := range inputs {
+		digest, crc, err := shecomp.CompressWithCRC32(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+
+		want, err := shecomp.Compress(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(want, digest) {
+			t.Errorf("digest = %s, want %s", digest, want)
+		}
+
+		raw, _ := hex.DecodeString(s)
+		if wantCRC := crc32.ChecksumIEEE(raw); crc != wantCRC {
+			t.Errorf("crc = %08x, want %08x", crc, wantCRC)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package shecomp
+
+import (
+	"crypto/aes"
+	"fmt"
+)
+
+// cmac calculates AES-CMAC defined in RFC 4493, which SHE uses as its MAC.
+func cmac(key, message []byte) ([]byte, error) {
+	cipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize aes cipher: %w", err)
+	}
+
+	// generate subkeys
+	k1 := make([]byte, blockSize)
+	cipher.Encrypt(k1, k1)
+	k1 = shiftLeft(k1)
+	k2 := shiftLeft(k1)
+
+	n := (len(message) + blockSize - 1) / blockSize
+	complete := n > 0 && len(message)%blockSize == 0
+	if n == 0 {
+		n = 1
+	}
+
+	// the last block is xored with the subkey
+	last := make([]byte, blockSize)
+	tail := message[(n-1)*blockSize:]
+	copy(last, tail)
+	k := k1
+	if !complete {
+		last[len(tail)] = 0x80
+		k = k2
+	}
+	if err = xor(last, last, k); err != nil {
+		return nil, err
+	}
+
+	x := make([]byte, blockSize)
+	for i := 0; i < n-1; i++ {
+		if err = xor(x, x, message[i*blockSize:(i+1)*blockSize]); err != nil {
+			return nil, err
+		}
+		cipher.Encrypt(x, x)
+	}
+	if err = xor(x, x, last); err != nil {
+		return nil, err
+	}
+	cipher.Encrypt(x, x)
+	return x, nil
+}
+
+// shiftLeft shifts the block left by one bit, and applies the constant R_128 on carry to derive the CMAC subkey.
+func shiftLeft(b []byte) []byte {
+	const rb = 0x87
+	out := make([]byte, len(b))
+	for i := 0; i < len(b)-1; i++ {
+		out[i] = b[i]<<1 | b[i+1]>>7
+	}
+	out[len(b)-1] = b[len(b)-1] << 1
+	if b[0]&0x80 != 0 {
+		out[len(b)-1] ^= rb
+	}
+	return out
+}
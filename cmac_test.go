@@ -0,0 +1,36 @@
+package shecomp
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestCMAC(t *testing.T) {
+	// test vectors from RFC 4493
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	message, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+
+	tests := []struct {
+		name string
+		len  int
+		want string
+	}{
+		{"empty message", 0, "bb1d6929e95937287fa37d129b756746"},
+		{"one block", 16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{"incomplete last block", 40, "dfa66747de9ae63030ca32611497c827"},
+		{"four blocks", 64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cmac(key, message[:tt.len])
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("cmac() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,15 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
 
 	"github.com/tenkoh/go-shecomp"
@@ -28,7 +32,198 @@ func compress(w io.Writer, r io.Reader, fn func(r io.Reader) ([]byte, error)) er
 	return nil
 }
 
-func run(c *cli.Context) error {
+// compressRaw is same as compress, but writes the raw bytes of the hexadecimal encoded result.
+func compressRaw(w io.Writer, r io.Reader, fn func(r io.Reader) ([]byte, error)) error {
+	o, err := fn(r)
+	if err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(string(o))
+	if err != nil {
+		return fmt.Errorf("failed to decode the result: %w", err)
+	}
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("failed to write the result: %w", err)
+	}
+	return nil
+}
+
+// maxLineLen is the maximum length of a line of the batch input in bytes, including the line break.
+// A line is held in memory while compressed, so it is far shorter than the longest message.
+var maxLineLen = 64 << 20
+
+// batch compresses each non-empty line of the input as a separate message, and writes one result per line.
+// A failed line is reported with its line number to errw and is skipped, unless failFast is set.
+// A line longer than maxLineLen stops the batch.
+func batch(w, errw io.Writer, r io.Reader, fn func(r io.Reader) ([]byte, error), failFast bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxLineLen)
+	failed := 0
+	line := 1
+	for ; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" {
+			continue
+		}
+		o, err := fn(strings.NewReader(s))
+		if err != nil {
+			if failFast {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			fmt.Fprintf(errw, "line %d: %v\n", line, err)
+			failed++
+			continue
+		}
+		fmt.Fprintln(w, string(o))
+	}
+	if err := sc.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("line %d: longer than %d bytes: %w", line, maxLineLen, err)
+	} else if err != nil {
+		return fmt.Errorf("failed to read the input: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d lines failed", failed)
+	}
+	return nil
+}
+
+// verify compresses the input and compares the digest with the expected one case-insensitively.
+// Nothing is written on success, and the digest is reported only in the error on mismatch.
+func verify(r io.Reader, fn func(r io.Reader) ([]byte, error), want string) error {
+	o, err := fn(r)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(string(o), want) {
+		return fmt.Errorf("digest mismatch: got %s, want %s", o, want)
+	}
+	return nil
+}
+
+// messageLen returns the length of the message in bytes, consuming the input.
+func messageLen(r io.Reader, binary bool) (uint64, error) {
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the input: %w", err)
+	}
+	if !binary {
+		if n%2 != 0 {
+			return 0, errors.New("the length of the hexadecimal encoded input must be even")
+		}
+		n /= 2
+	}
+	return uint64(n), nil
+}
+
+// estimate prints the number of blocks which the compression with padding processes for the input.
+func estimate(w io.Writer, r io.Reader, binary bool) error {
+	n, err := messageLen(r, binary)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(w, shecomp.EstimateBlocks(n))
+	return nil
+}
+
+// layout prints how the input maps onto the blocks of the compression with padding.
+func layout(w io.Writer, r io.Reader, binary bool) error {
+	n, err := messageLen(r, binary)
+	if err != nil {
+		return err
+	}
+	blocks := shecomp.EstimateBlocks(n)
+	// the padding has a block of its own when the message does not reach the last block
+	extra := "no"
+	if uint64(blocks-1)*16 >= n {
+		extra = "yes"
+	}
+	fmt.Fprintf(w, "message bytes: %d\n", n)
+	fmt.Fprintf(w, "padding bytes: %d\n", uint64(blocks)*16-n)
+	fmt.Fprintf(w, "total blocks: %d\n", blocks)
+	fmt.Fprintf(w, "extra padding block: %s\n", extra)
+	return nil
+}
+
+// manifest compresses each file with padding, and writes the binary manifest of the raw digests.
+func manifest(w io.Writer, comp *shecomp.Compressor, paths []string) error {
+	digests := make(map[string][16]byte, len(paths))
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return fmt.Errorf("failed to open the input file %s: %w", p, err)
+		}
+		d, err := comp.CompressRaw(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to compress %s: %w", p, err)
+		}
+		digests[p] = [16]byte(d)
+	}
+	return shecomp.WriteManifest(w, digests)
+}
+
+// lazyFile creates the file at the first Write, so that an existing file is not truncated
+// when the command fails before writing anything, e.g. on an invalid flag or input.
+type lazyFile struct {
+	path string
+	f    *os.File
+	err  error // the error of the creation, reported by Close too since fmt.Fprint drops it
+}
+
+func (l *lazyFile) Write(p []byte) (int, error) {
+	if l.f == nil && l.err == nil {
+		f, err := os.Create(l.path)
+		if err != nil {
+			l.err = fmt.Errorf("failed to create the output file %s: %w", l.path, err)
+		} else {
+			l.f = f
+		}
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	return l.f.Write(p)
+}
+
+// Close closes the file if it has been created.
+func (l *lazyFile) Close() error {
+	if l.err != nil {
+		return l.err
+	}
+	if l.f == nil {
+		return nil
+	}
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("failed to close the output file %s: %w", l.path, err)
+	}
+	return nil
+}
+
+func run(c *cli.Context) (err error) {
+	var opts []shecomp.Option
+	if c.Bool("binary") {
+		opts = append(opts, shecomp.WithBinaryInput())
+	}
+	comp := shecomp.NewCompressor(opts...)
+
+	w := c.App.Writer
+	if p := c.String("output"); p != "" {
+		f := &lazyFile{path: p}
+		defer func() {
+			if cerr := f.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+
+	if c.Bool("manifest-binary") {
+		if c.String("input") != "" || len(c.Args().Slice()) == 0 {
+			return errors.New("the manifest-binary flag requires the input files as the arguments")
+		}
+		return manifest(w, comp, c.Args().Slice())
+	}
+
 	// switch the input source
 	// if both the input file and the hexadecimal encoded string are specified, return error.
 	if c.String("input") != "" && len(c.Args().Slice()) > 0 {
@@ -36,7 +231,7 @@ func run(c *cli.Context) error {
 	}
 
 	var r io.Reader
-	r = os.Stdin
+	r = c.App.Reader
 	if c.String("input") != "" {
 		f, err := os.Open(c.String("input"))
 		if err != nil {
@@ -49,32 +244,79 @@ func run(c *cli.Context) error {
 		r = strings.NewReader(c.Args().Slice()[0])
 	}
 
+	if c.Bool("estimate") {
+		if err := estimate(w, r, c.Bool("binary")); err != nil {
+			return err
+		}
+		if isTerminal(w) {
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
+	if c.Bool("layout") {
+		return layout(w, r, c.Bool("binary"))
+	}
+
 	// switch the output mode
 	if c.Bool("padding") && c.Bool("nopad") {
 		return errors.New("both the padding and nopad flags are specified")
 	}
+	// the context is canceled by SIGINT, to stop reading a long stream.
+	ctx := c.Context
 	var fn func(r io.Reader) ([]byte, error)
 	if c.Bool("padding") {
-		fn = shecomp.Padding
+		fn = comp.Padding
 	} else if c.Bool("nopad") {
-		fn = shecomp.CompressWithoutPadding
+		fn = func(r io.Reader) ([]byte, error) {
+			return comp.CompressWithoutPaddingContext(ctx, r)
+		}
 	} else {
-		fn = shecomp.Compress
+		fn = func(r io.Reader) ([]byte, error) {
+			return comp.CompressContext(ctx, r)
+		}
+	}
+
+	if c.Bool("batch") {
+		if c.Bool("binary") || c.Bool("raw") || c.String("verify") != "" {
+			return errors.New("the batch flag can not be combined with the binary, raw or verify flags")
+		}
+		return batch(w, c.App.ErrWriter, r, fn, c.Bool("fail-fast"))
+	}
+
+	if want := c.String("verify"); want != "" {
+		if c.Bool("padding") {
+			return errors.New("both the verify and padding flags are specified")
+		}
+		return verify(r, fn, want)
+	}
+
+	if c.Bool("raw") {
+		if isTerminal(w) {
+			return errors.New("refusing to write the raw binary output to a terminal, use the output flag or redirect it")
+		}
+		return compressRaw(w, r, fn)
 	}
 
-	if err := compress(os.Stdout, r, fn); err != nil {
+	if err := compress(w, r, fn); err != nil {
 		return err
 	}
 
-	// if the output is a terminal, add a new line
-	if term.IsTerminal(int(os.Stdout.Fd())) {
-		fmt.Println()
+	// if the output is a terminal, add a new line.
+	// otherwise the output is exactly the digest, so that it can be captured by scripts.
+	if isTerminal(w) {
+		fmt.Fprintln(w)
 	}
 	return nil
 }
 
-func main() {
-	app := &cli.App{
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+func newApp() *cli.App {
+	return &cli.App{
 		Name:    "shecomp",
 		Usage:   "shecomp [options] [hexadecimal encoded string]",
 		Version: version,
@@ -93,11 +335,65 @@ func main() {
 				Name:  "nopad",
 				Usage: "compress the input data without padding",
 			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "write the output to the file instead of stdout",
+			},
+			&cli.BoolFlag{
+				Name:  "raw",
+				Usage: "write the raw 16 bytes digest or the raw padding instead of hexadecimal encoded text",
+			},
+			&cli.StringFlag{
+				Name:    "verify",
+				Aliases: []string{"V"},
+				Usage:   "compare the digest with the given hexadecimal digest instead of printing it, and fail on mismatch",
+			},
+			&cli.BoolFlag{
+				Name:  "batch",
+				Usage: "compress each non-empty line of the input as a separate message, and print one result per line",
+			},
+			&cli.BoolFlag{
+				Name:  "fail-fast",
+				Usage: "stop the batch at the first line which fails",
+			},
+			&cli.BoolFlag{
+				Name:  "binary",
+				Usage: "read the input as raw binary instead of hexadecimal encoded text",
+			},
+			&cli.BoolFlag{
+				Name:  "estimate",
+				Usage: "print the number of blocks to be processed instead of the digest",
+			},
+			&cli.BoolFlag{
+				Name:  "layout",
+				Usage: "print the message length, the padding length and the number of blocks instead of the digest",
+			},
+			&cli.BoolFlag{
+				Name:  "manifest-binary",
+				Usage: "compress the files given as the arguments and write the binary manifest of their digests",
+			},
 		},
 		Action: run,
 	}
+}
 
-	if err := app.Run(os.Args); err != nil {
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		// restore the default behavior, so that the second SIGINT terminates even a blocking read.
+		<-ctx.Done()
+		stop()
+	}()
+
+	app := newApp()
+	if err := app.RunContext(ctx, os.Args); err != nil {
+		if errors.Is(err, context.Canceled) {
+			// the error tells how many bytes have been processed before the interruption.
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(130)
+		}
 		log.Fatal(err)
 	}
 }
@@ -2,6 +2,12 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -22,3 +28,316 @@ func TestCompress(t *testing.T) {
 		t.Errorf("got %s, want %s", b.String(), want)
 	}
 }
+
+func TestRunNonTerminalOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			"compress",
+			[]string{"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			"c7277a0dc1fb853b5f4d9cbd26be40c6",
+		},
+		{
+			"estimate",
+			[]string{"--estimate", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			"3",
+		},
+		{
+			"padding",
+			[]string{"--padding", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			"80000000000000000000000000000100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			app := newApp()
+			app.Writer = &b
+			if err := app.Run(append([]string{"shecomp"}, tt.args...)); err != nil {
+				t.Error(err)
+				return
+			}
+			// no trailing newline must be emitted when the output is not a terminal
+			if b.Len() != len(tt.want) {
+				t.Errorf("got %d bytes %q, want exactly %d bytes", b.Len(), b.String(), len(tt.want))
+			}
+			if b.String() != tt.want {
+				t.Errorf("got %s, want %s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			"example described in SHE specification",
+			[]string{"--layout", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			"message bytes: 32\npadding bytes: 16\ntotal blocks: 3\nextra padding block: yes\n",
+		},
+		{
+			"padding in the last message block",
+			[]string{"--layout", "6bc1bee22e409f96e93d7e117393172aae2d8a57"},
+			"message bytes: 20\npadding bytes: 12\ntotal blocks: 2\nextra padding block: no\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			app := newApp()
+			app.Writer = &b
+			if err := app.Run(append([]string{"shecomp"}, tt.args...)); err != nil {
+				t.Error(err)
+				return
+			}
+			if b.String() != tt.want {
+				t.Errorf("got %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunVerify(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "msg.hex")
+	if err := os.WriteFile(path, []byte("000102030405060708090a0b0c0d0e0f010153484500800000000000000000b0"), 0o600); err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			"match",
+			[]string{"--verify", "c7277a0dc1fb853b5f4d9cbd26be40c6", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			false,
+		},
+		{
+			"match in upper case",
+			[]string{"-V", "C7277A0DC1FB853B5F4D9CBD26BE40C6", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			false,
+		},
+		{
+			"match without padding from a file",
+			[]string{"--nopad", "-i", path, "-V", "118a46447a770d87828a69c222e2d17e"},
+			false,
+		},
+		{
+			"mismatch",
+			[]string{"--verify", "00000000000000000000000000000000", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			true,
+		},
+		{
+			"with padding",
+			[]string{"--padding", "--verify", "80000000000000000000000000000100", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			app := newApp()
+			app.Writer = &b
+			err := app.Run(append([]string{"shecomp"}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+			// the digest must not be printed, whether it matches or not
+			if b.Len() != 0 {
+				t.Errorf("got output %q, want nothing", b.String())
+			}
+		})
+	}
+}
+
+func TestRunOutputFile(t *testing.T) {
+	msg := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	digest, _ := hex.DecodeString("c7277a0dc1fb853b5f4d9cbd26be40c6")
+	pad, _ := hex.DecodeString("80000000000000000000000000000100")
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		args []string
+		want []byte
+	}{
+		{"hex", []string{msg}, []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")},
+		{"raw", []string{"--raw", msg}, digest},
+		{"raw padding", []string{"--raw", "--padding", msg}, pad},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_"))
+			var b bytes.Buffer
+			app := newApp()
+			app.Writer = &b
+			if err := app.Run(append([]string{"shecomp", "-o", path}, tt.args...)); err != nil {
+				t.Error(err)
+				return
+			}
+			if b.Len() != 0 {
+				t.Errorf("got stdout %q, want nothing", b.String())
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got %x, want %x", got, tt.want)
+			}
+		})
+	}
+
+	// the existing output file is not truncated when the command fails
+	path := filepath.Join(dir, "existing")
+	if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"012"}, {"--padding", "--nopad", msg}} {
+		app := newApp()
+		app.Writer = io.Discard
+		if err := app.Run(append([]string{"shecomp", "-o", path}, args...)); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+		if got, _ := os.ReadFile(path); string(got) != "keep" {
+			t.Errorf("%v: the output file is overwritten with %q", args, got)
+		}
+	}
+
+	// the raw digest can be written to stdout when it is not a terminal
+	var b bytes.Buffer
+	app := newApp()
+	app.Writer = &b
+	if err := app.Run([]string{"shecomp", "--raw", msg}); err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(b.Bytes(), digest) {
+		t.Errorf("got %x, want %x", b.Bytes(), digest)
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	input := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51\n\n012\r\n000102030405060708090a0b0c0d0e0f010153484500800000000000000000b0\n"
+	first, _ := shecomp.Compress(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+	last, _ := shecomp.Compress(strings.NewReader("000102030405060708090a0b0c0d0e0f010153484500800000000000000000b0"))
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"continue", []string{"--batch"}, string(first) + "\n" + string(last) + "\n", "line 3: "},
+		{"fail fast", []string{"--batch", "--fail-fast"}, string(first) + "\n", ""},
+		{"without padding", []string{"--batch", "--nopad"}, "1976aed18c2f3746165e5de2c4c5d446\n118a46447a770d87828a69c222e2d17e\n", "line 3: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b, e bytes.Buffer
+			app := newApp()
+			app.Reader = strings.NewReader(input)
+			app.Writer = &b
+			app.ErrWriter = &e
+			if err := app.Run(append([]string{"shecomp"}, tt.args...)); err == nil {
+				t.Error("expected error for the invalid lines")
+			}
+			if b.String() != tt.want {
+				t.Errorf("got %q, want %q", b.String(), tt.want)
+			}
+			if !strings.HasPrefix(e.String(), tt.wantErr) {
+				t.Errorf("got stderr %q, want prefix %q", e.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBatchLongLine(t *testing.T) {
+	defer func(n int) { maxLineLen = n }(maxLineLen)
+	maxLineLen = 64
+
+	input := "0123\n" + strings.Repeat("ab", 40) + "\n4567\n"
+	var b, e bytes.Buffer
+	err := batch(&b, &e, strings.NewReader(input), shecomp.Compress, false)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Errorf("expected error for line 2, got %v", err)
+	}
+	if want, _ := shecomp.Compress(strings.NewReader("0123")); b.String() != string(want)+"\n" {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestRunBinaryInput(t *testing.T) {
+	raw, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	want := "c7277a0dc1fb853b5f4d9cbd26be40c6"
+
+	var b bytes.Buffer
+	app := newApp()
+	app.Reader = bytes.NewReader(raw)
+	app.Writer = &b
+	if err := app.Run([]string{"shecomp", "--binary"}); err != nil {
+		t.Error(err)
+		return
+	}
+	if b.String() != want {
+		t.Errorf("got %s, want %s", b.String(), want)
+	}
+}
+
+func TestRunManifestBinary(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "a.hex")
+	if err := os.WriteFile(p, []byte("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	app := newApp()
+	app.Writer = &b
+	if err := app.Run([]string{"shecomp", "--manifest-binary", p}); err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := shecomp.ReadManifest(&b)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if d, ok := got[p]; !ok || hex.EncodeToString(d[:]) != "c7277a0dc1fb853b5f4d9cbd26be40c6" {
+		t.Errorf("got %x for %s in the manifest %v", d, p, got)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	// simulate SIGINT, which cancels the context given to the app.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var b bytes.Buffer
+	app := newApp()
+	app.Reader = bytes.NewReader(make([]byte, 1024))
+	app.Writer = &b
+	err := app.RunContext(ctx, []string{"shecomp", "--binary"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected no output, got %q", b.String())
+	}
+}
@@ -0,0 +1,508 @@
+package shecomp
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Error kinds reported to Metrics.IncError.
+const (
+	ErrorKindLargePlainText = "large_plain_text"
+	ErrorKindNeedPadding    = "need_padding"
+	ErrorKindInvalidHex     = "invalid_hex"
+	ErrorKindOther          = "other"
+)
+
+// Metrics is an observability hook for a Compressor.
+// IncCompress is called on each successful compression,
+// and IncError is called on each failed one with the kind of the error (one of ErrorKind* constants).
+// The implementation must be safe for concurrent use if the Compressor is shared between goroutines.
+type Metrics interface {
+	IncCompress()
+	IncError(kind string)
+}
+
+// Option configures a Compressor.
+type Option func(*Compressor)
+
+// WithMetrics sets the metrics hook called on each compression.
+func WithMetrics(m Metrics) Option {
+	return func(c *Compressor) {
+		c.metrics = m
+	}
+}
+
+// WithBinaryInput makes the Compressor read raw binary input instead of hexadecimal encoded text.
+// The output is still encoded in hexadecimal.
+func WithBinaryInput() Option {
+	return func(c *Compressor) {
+		c.binary = true
+	}
+}
+
+// KeyScheduleCache caches the AES key schedules keyed by the chaining state.
+// Since the chaining state changes on every block, the cache hits only across messages sharing a prefix,
+// such as many messages starting with the same header.
+// Implementations must copy the key if they retain it, and must be safe for concurrent use
+// if the Compressor is shared between goroutines.
+type KeyScheduleCache interface {
+	Get(key []byte) (cipher.Block, bool)
+	Put(key []byte, b cipher.Block)
+}
+
+// WithKeyScheduleCache makes the Compressor consult the cache before initializing the AES cipher of each block.
+func WithKeyScheduleCache(cache KeyScheduleCache) Option {
+	return func(c *Compressor) {
+		c.cache = cache
+	}
+}
+
+// WithStrict makes the Compressor verify the internal invariants of each compression,
+// such as the padded message being multiple of the block size and the length field of the padding,
+// and return ErrInvariantViolated if any of them does not hold.
+func WithStrict() Option {
+	return func(c *Compressor) {
+		c.strict = true
+	}
+}
+
+// WithWhitespaceTolerance makes the Compressor ignore the whitespaces (space, tab, CR and LF) in the hexadecimal encoded input,
+// such as the line breaks of a hex dump wrapped at a fixed column width.
+// A whitespace may appear anywhere, even between the two digits of a byte.
+// It has no effect with WithBinaryInput.
+func WithWhitespaceTolerance() Option {
+	return func(c *Compressor) {
+		c.skipSpace = true
+	}
+}
+
+// WithBlockWhitening makes the Compressor XOR whiten into each input block before the Miyaguchi-Preneel step,
+// including the blocks of the padding. The chaining state is not whitened.
+// This is not a part of SHE specification: a non-zero whiten produces non-standard digests,
+// which some designs use for the domain separation. A zero whiten is same as no whitening.
+func WithBlockWhitening(whiten [blockSize]byte) Option {
+	return func(c *Compressor) {
+		c.whiten = &whiten
+	}
+}
+
+// WithLengthWarning makes the Compressor call cb once per compression with padding,
+// when the length of the message read so far exceeds threshold (e.g. 0.9) of the maximum length,
+// with the used fraction of the maximum length, before it reaches ErrLargePlainText.
+// The length is checked after each block.
+func WithLengthWarning(threshold float64, cb func(usedFraction float64)) Option {
+	return func(c *Compressor) {
+		c.warnThreshold = threshold
+		c.warn = cb
+	}
+}
+
+// WithoutExtraPaddingBlock makes the Compressor omit the whole padding block if omit is true
+// and the message is not empty and multiple of the block size, reproducing a device which does so.
+// Then the digest is same as the one of CompressWithoutPadding, and Padding returns the empty padding.
+// It applies to Sum after Write too.
+// The other messages are padded as usual. This is not conformant to SHE specification,
+// which always appends the padding block to such messages.
+func WithoutExtraPaddingBlock(omit bool) Option {
+	return func(c *Compressor) {
+		c.noExtra = omit
+	}
+}
+
+// ErrZeroBlock is returned when the input contains an all-zero block, if WithForbidZeroBlock is set.
+var ErrZeroBlock = errors.New("the input contains an all-zero block")
+
+// WithForbidZeroBlock makes the Compressor return ErrZeroBlock if forbid is true
+// and any complete block of the message in the Compress methods is all zeros, which often indicates uninitialized memory.
+// The blocks containing the padding added by the Compressor are not checked.
+func WithForbidZeroBlock(forbid bool) Option {
+	return func(c *Compressor) {
+		c.forbidZero = forbid
+	}
+}
+
+// WithBlockValidator makes the Compressor call v before compressing each block of the Compress methods,
+// with the index of the block counted from zero, the block, and whether the block contains the padding.
+// If v returns an error, the compression is aborted with the error wrapped.
+// v must not modify or retain the block.
+func WithBlockValidator(v func(index int, block []byte, padded bool) error) Option {
+	return func(c *Compressor) {
+		c.validate = v
+	}
+}
+
+// ErrBadGranularity is returned when the length of the message is not multiple of the granularity
+// required by WithRequiredGranularity.
+var ErrBadGranularity = errors.New("the length of the message is not multiple of the required granularity")
+
+// WithRequiredGranularity makes the Compressor reject the message whose length in bytes after decoding
+// is not multiple of n, with ErrBadGranularity. The check is done before the padding is added,
+// so it is independent of the block size. It applies to the methods adding the padding.
+// n <= 0 means no requirement, which is the default.
+func WithRequiredGranularity(n int) Option {
+	return func(c *Compressor) {
+		if n < 0 {
+			n = 0
+		}
+		c.granule = uint64(n)
+	}
+}
+
+var _ hash.Hash = (*Compressor)(nil)
+
+// New returns a new hash.Hash computing the SHE compression with padding over the raw message bytes,
+// which is a Compressor with the default options.
+func New() hash.Hash {
+	return new(Compressor)
+}
+
+// Compressor compresses the input data using AES Miyaguchi-Preenel mode with configurable options.
+// The zero value is ready to use and behaves same as the package level functions.
+//
+// Besides the Compress methods which consume an io.Reader at once,
+// a Compressor accepts raw (not hexadecimal encoded) message bytes incrementally via Write,
+// and returns the raw digest via Sum.
+type Compressor struct {
+	metrics       Metrics
+	strict        bool
+	binary        bool
+	cache         KeyScheduleCache
+	granule       uint64
+	validate      func(index int, block []byte, padded bool) error
+	skipSpace     bool
+	skipPrefix    bool // remove the 0x prefixes of the words too, with skipSpace
+	whiten        *[blockSize]byte
+	forbidZero    bool
+	warn          func(usedFraction float64)
+	warnThreshold float64
+	random        io.Reader
+	noExtra       bool
+
+	// limit overrides the maximum message length in bytes if not zero, for testing.
+	limit uint64
+	// observe is called after each block, and aborts the compression on an error.
+	observe func(index int, block, state []byte) error
+
+	// state of the incremental compression via Write
+	state [blockSize]byte
+	buf   [blockSize]byte
+	nbuf  int
+	n     uint64
+	// scratch of absorb
+	white [blockSize]byte
+	next  [blockSize]byte
+}
+
+// NewCompressor returns a Compressor configured by the given options.
+func NewCompressor(opts ...Option) *Compressor {
+	c := &Compressor{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Compress is same as the package level Compress function but applies the options of c.
+func (c *Compressor) Compress(r io.Reader) ([]byte, error) {
+	return c.CompressContext(context.Background(), r)
+}
+
+// CompressContext is same as the package level CompressContext function but applies the options of c.
+func (c *Compressor) CompressContext(ctx context.Context, r io.Reader) ([]byte, error) {
+	return c.runContext(ctx, c.paddingReader(r))
+}
+
+// CompressRaw is same as the package level CompressRaw function but applies the options of c.
+func (c *Compressor) CompressRaw(r io.Reader) ([]byte, error) {
+	return c.digest(context.Background(), c.paddingReader(r))
+}
+
+// CompressWithoutPadding is same as the package level CompressWithoutPadding function but applies the options of c.
+func (c *Compressor) CompressWithoutPadding(r io.Reader) ([]byte, error) {
+	return c.CompressWithoutPaddingContext(context.Background(), r)
+}
+
+// CompressWithoutPaddingContext is same as CompressWithoutPadding, but stops reading the input when ctx is done.
+func (c *Compressor) CompressWithoutPaddingContext(ctx context.Context, r io.Reader) ([]byte, error) {
+	return c.runContext(ctx, &noPaddingReader{r: c.input(r), decode: c.decoder()})
+}
+
+// Padding is same as the package level Padding function but applies the options of c.
+func (c *Compressor) Padding(r io.Reader) ([]byte, error) {
+	return paddingOf(c.paddingReader(r))
+}
+
+func (c *Compressor) decoder() decoder {
+	if c.binary {
+		return rawRead
+	}
+	return newHexDecoder()
+}
+
+// input wraps r to skip the whitespaces if required.
+func (c *Compressor) input(r io.Reader) io.Reader {
+	if c.skipSpace && !c.binary {
+		return &spaceSkipper{r: r, prefix: c.skipPrefix}
+	}
+	return r
+}
+
+func (c *Compressor) paddingReader(r io.Reader) *paddingReader {
+	br := newPaddingReader(c.input(r))
+	br.decode = c.decoder()
+	br.granule = c.granule
+	br.limit = c.limit
+	br.noExtra = c.noExtra
+	if c.warn != nil {
+		br.warn = &lengthWarning{threshold: c.warnThreshold, cb: c.warn}
+	}
+	return br
+}
+
+func (c *Compressor) run(br BlockReader) ([]byte, error) {
+	return c.runContext(context.Background(), br)
+}
+
+func (c *Compressor) runContext(ctx context.Context, br BlockReader) ([]byte, error) {
+	out, err := c.digest(ctx, br)
+	if err != nil {
+		return nil, err
+	}
+	return encodeHex(out), nil
+}
+
+// digest runs the compression over br and returns the raw digest.
+func (c *Compressor) digest(ctx context.Context, br BlockReader) ([]byte, error) {
+	out, err := c.compressContext(ctx, br)
+	if err == nil && c.strict {
+		err = verifyInvariants(br, out)
+	}
+	c.record(err)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Compressor) record(err error) {
+	if c.metrics == nil {
+		return
+	}
+	if err == nil {
+		c.metrics.IncCompress()
+		return
+	}
+	c.metrics.IncError(errorKind(err))
+}
+
+func errorKind(err error) string {
+	var invalidByte hex.InvalidByteError
+	switch {
+	case errors.Is(err, ErrLargePlainText):
+		return ErrorKindLargePlainText
+	case errors.Is(err, ErrNeedPadding):
+		return ErrorKindNeedPadding
+	case errors.Is(err, hex.ErrLength), errors.As(err, &invalidByte):
+		return ErrorKindInvalidHex
+	default:
+		return ErrorKindOther
+	}
+}
+
+// Write absorbs the raw message bytes into the running state.
+// Completed blocks are compressed immediately, and the rest is buffered until the next Write or Sum.
+// If the total length exceeds 1<<40 - 1 in bit, it returns ErrLargePlainText and absorbs nothing.
+func (c *Compressor) Write(p []byte) (int, error) {
+	if uint64(len(p)) > maxBitLength/8-c.n {
+		return 0, ErrLargePlainText
+	}
+	c.n += uint64(len(p))
+	written := len(p)
+
+	if c.nbuf > 0 {
+		k := copy(c.buf[c.nbuf:], p)
+		c.nbuf += k
+		p = p[k:]
+		if c.nbuf < blockSize {
+			return written, nil
+		}
+		c.absorb(c.buf[:])
+		c.nbuf = 0
+	}
+	for len(p) >= blockSize {
+		c.absorb(p[:blockSize])
+		p = p[blockSize:]
+	}
+	c.nbuf = copy(c.buf[:], p)
+	return written, nil
+}
+
+// Sum appends the raw digest of the message written so far to b and returns the resulting slice.
+// The padding is applied to a copy of the state, so that Sum does not change the running state.
+// Same as Compress, the padding block is omitted with WithoutExtraPaddingBlock(true) if the message is not empty
+// and multiple of the block size.
+func (c *Compressor) Sum(b []byte) []byte {
+	if c.noExtra && c.nbuf == 0 && c.n > 0 {
+		return append(b, c.state[:]...)
+	}
+	d := *c
+	var last [2 * blockSize]byte
+	copy(last[:], d.buf[:d.nbuf])
+	end := d.nbuf + padLen(d.nbuf)
+	putPadding(last[d.nbuf:end], d.n)
+	for i := 0; i < end; i += blockSize {
+		d.absorb(last[i : i+blockSize])
+	}
+	return append(b, d.state[:]...)
+}
+
+// Size returns the length of the digest in bytes, which is 16.
+func (c *Compressor) Size() int {
+	return blockSize
+}
+
+// BlockSize returns the block size of the compression in bytes, which is 16.
+func (c *Compressor) BlockSize() int {
+	return blockSize
+}
+
+// Reset clears the running state to compress a new message.
+// The options are kept.
+func (c *Compressor) Reset() {
+	c.state = [blockSize]byte{}
+	c.buf = [blockSize]byte{}
+	c.nbuf = 0
+	c.n = 0
+}
+
+// compressContext runs the block loop over br, and stops when ctx is done.
+func (c *Compressor) compressContext(ctx context.Context, br BlockReader) ([]byte, error) {
+	src := make([]byte, blockSize)
+	out := make([]byte, blockSize)
+	// the next state is written into next, and swapped with out, so that no buffer is allocated per block.
+	next := make([]byte, blockSize)
+
+	for blocks := 0; ; blocks++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("compression canceled after %d bytes: %w", blocks*blockSize, err)
+		}
+		if err := br.Block(src); err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return nil, fmt.Errorf("could not read from reader: %w", err)
+		}
+		if c.validate != nil {
+			if err := c.validate(blocks, src, isPadded(br)); err != nil {
+				return nil, fmt.Errorf("block %d is rejected: %w", blocks, err)
+			}
+		}
+		if c.forbidZero && !isPadded(br) && isZero(src) {
+			return nil, fmt.Errorf("%w: block %d", ErrZeroBlock, blocks)
+		}
+		if c.whiten != nil {
+			for i := range src {
+				src[i] ^= c.whiten[i]
+			}
+		}
+
+		if err := encryptInto(c.newCipher, next, src, out); err != nil {
+			return nil, fmt.Errorf("failed to encrypt: %w", err)
+		}
+		out, next = next, out
+		if c.observe != nil {
+			if err := c.observe(blocks, src, out); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// newCipher returns the AES cipher keyed by the chaining state, consulting the key schedule cache if set.
+func (c *Compressor) newCipher(key []byte) (cipher.Block, error) {
+	if c.cache != nil {
+		if b, ok := c.cache.Get(key); ok {
+			return b, nil
+		}
+	}
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache != nil {
+		c.cache.Put(key, b)
+	}
+	return b, nil
+}
+
+// absorb compresses the block into the running state, writing the intermediate values into the scratch of c
+// same as the block loop, so that only the cipher keyed by the state is allocated per block.
+func (c *Compressor) absorb(block []byte) {
+	if c.whiten != nil {
+		if err := xor(c.white[:], block, c.whiten[:]); err != nil {
+			// unreachable: the lengths of the block and the whitening are always blockSize
+			panic(err)
+		}
+		block = c.white[:]
+	}
+	if err := encryptInto(c.newCipher, c.next[:], block, c.state[:]); err != nil {
+		// unreachable: the lengths are always blockSize, and aes.NewCipher accepts any 16 bytes key
+		panic(err)
+	}
+	c.state = c.next
+}
+
+const (
+	marshalMagic = "she\x01"
+	marshaledLen = len(marshalMagic) + 2*blockSize + 8
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// It captures the running state of the incremental compression via Write:
+// the chaining value, the buffered incomplete block, and the number of bytes written.
+// The options are not included.
+func (c *Compressor) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, marshaledLen)
+	b = append(b, marshalMagic...)
+	b = append(b, c.state[:]...)
+	b = append(b, c.buf[:c.nbuf]...)
+	b = b[:len(b)+blockSize-c.nbuf] // zero-fill the rest of the buffer
+	b = binary.BigEndian.AppendUint64(b, c.n)
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// It restores the running state captured by MarshalBinary, keeping the options of c,
+// so that the compression can resume e.g. after a restart of the process.
+func (c *Compressor) UnmarshalBinary(b []byte) error {
+	if len(b) != marshaledLen || string(b[:len(marshalMagic)]) != marshalMagic {
+		return errors.New("invalid compressor state")
+	}
+	b = b[len(marshalMagic):]
+	copy(c.state[:], b[:blockSize])
+	b = b[blockSize:]
+	copy(c.buf[:], b[:blockSize])
+	b = b[blockSize:]
+	c.n = binary.BigEndian.Uint64(b)
+	if c.n > maxBitLength/8 {
+		return ErrLargePlainText
+	}
+	c.nbuf = int(c.n % blockSize)
+	return nil
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
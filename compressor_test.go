@@ -0,0 +1,434 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+type mockMetrics struct {
+	mu        sync.Mutex
+	compress  int
+	errorKind []string
+}
+
+func (m *mockMetrics) IncCompress() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.compress++
+}
+
+func (m *mockMetrics) IncError(kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errorKind = append(m.errorKind, kind)
+}
+
+func TestCompressorMetrics(t *testing.T) {
+	m := &mockMetrics{}
+	c := shecomp.NewCompressor(shecomp.WithMetrics(m))
+
+	inputs := []string{
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+		"",
+		"0123456789abcdef",
+	}
+	for _, s := range inputs {
+		if _, err := c.Compress(strings.NewReader(s)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if _, err := c.Compress(strings.NewReader("zz")); err == nil {
+		t.Error("expected error for invalid hexadecimal input")
+	}
+	if _, err := c.CompressWithoutPadding(strings.NewReader("00")); err == nil {
+		t.Error("expected error for the input without padding")
+	}
+
+	if m.compress != len(inputs) {
+		t.Errorf("IncCompress called %d times, want %d", m.compress, len(inputs))
+	}
+	want := []string{shecomp.ErrorKindInvalidHex, shecomp.ErrorKindNeedPadding}
+	if !reflect.DeepEqual(m.errorKind, want) {
+		t.Errorf("IncError called with %v, want %v", m.errorKind, want)
+	}
+}
+
+func TestCompressorZeroValue(t *testing.T) {
+	var c shecomp.Compressor
+	got, err := c.Compress(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Compress() = %v, want %v", got, want)
+	}
+}
+
+func TestCompressorWrite(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	raw, _ := hex.DecodeString(s)
+	want := "c7277a0dc1fb853b5f4d9cbd26be40c6"
+
+	var c shecomp.Compressor
+	for _, chunk := range [][]byte{raw[:3], raw[3:20], raw[20:21], raw[21:]} {
+		if _, err := c.Write(chunk); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+	}
+	if got := hex.EncodeToString(c.Sum(nil)); got != want {
+		t.Errorf("Sum() = %s, want %s", got, want)
+	}
+	// Sum must not change the running state
+	if got := hex.EncodeToString(c.Sum(nil)); got != want {
+		t.Errorf("second Sum() = %s, want %s", got, want)
+	}
+
+	c.Reset()
+	empty, _ := shecomp.Compress(strings.NewReader(""))
+	if got := hex.EncodeToString(c.Sum(nil)); got != string(empty) {
+		t.Errorf("Sum() after Reset = %s, want %s", got, empty)
+	}
+}
+
+func TestCompressorBinaryInput(t *testing.T) {
+	raw, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")
+
+	c := shecomp.NewCompressor(shecomp.WithBinaryInput())
+	got, err := c.Compress(bytes.NewReader(raw))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Compress() = %s, want %s", got, want)
+	}
+}
+
+// infiniteReader returns zero bytes forever.
+type infiniteReader struct{}
+
+func (infiniteReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = '0'
+	}
+	return len(p), nil
+}
+
+func TestCompressContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := shecomp.CompressContext(ctx, infiniteReader{})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("CompressContext did not return after the cancellation")
+	}
+}
+
+func TestCompressWithoutPaddingContextTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := shecomp.CompressWithoutPaddingContext(ctx, infiniteReader{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("CompressWithoutPaddingContext returned %v after the deadline", d)
+	}
+}
+
+func TestCompressorMarshalBinary(t *testing.T) {
+	raw, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	want := "c7277a0dc1fb853b5f4d9cbd26be40c6"
+
+	// midway points inside a block, at a block boundary, and at the start
+	for _, split := range []int{0, 7, 16, 21} {
+		var c shecomp.Compressor
+		c.Write(raw[:split])
+		state, err := c.MarshalBinary()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+
+		resumed := shecomp.NewCompressor()
+		if err := resumed.UnmarshalBinary(state); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		resumed.Write(raw[split:])
+		if got := hex.EncodeToString(resumed.Sum(nil)); got != want {
+			t.Errorf("split at %d: Sum() = %s, want %s", split, got, want)
+		}
+	}
+
+	if err := shecomp.NewCompressor().UnmarshalBinary([]byte("she")); err == nil {
+		t.Error("expected error for invalid state")
+	}
+}
+
+func TestCompressorWriteAllocs(t *testing.T) {
+	p := make([]byte, 4*16)
+	for _, c := range []*shecomp.Compressor{
+		shecomp.NewCompressor(),
+		shecomp.NewCompressor(shecomp.WithBlockWhitening([16]byte{1})),
+	} {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := c.Write(p); err != nil {
+				t.Fatal(err)
+			}
+		})
+		// only aes.NewCipher allocates, once per block
+		if allocs > 4 {
+			t.Errorf("Write of 4 blocks allocates %v times, want at most 4", allocs)
+		}
+	}
+}
+
+func TestCompressorMarshalBinaryLength(t *testing.T) {
+	state, err := shecomp.NewCompressor().MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := state[len(state)-8:]
+
+	// 1<<61 bytes overflows to 0 in bit
+	binary.BigEndian.PutUint64(n, 1<<61)
+	if err := shecomp.NewCompressor().UnmarshalBinary(state); !errors.Is(err, shecomp.ErrLargePlainText) {
+		t.Errorf("1<<61 bytes: expected ErrLargePlainText, got %v", err)
+	}
+
+	// the longest message accepts no more byte
+	binary.BigEndian.PutUint64(n, (1<<40-1)/8)
+	c := shecomp.NewCompressor()
+	if err := c.UnmarshalBinary(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.Write([]byte{0}); !errors.Is(err, shecomp.ErrLargePlainText) {
+		t.Errorf("expected ErrLargePlainText, got %v", err)
+	}
+}
+
+func TestCompressorRequiredGranularity(t *testing.T) {
+	c := shecomp.NewCompressor(shecomp.WithRequiredGranularity(32))
+
+	got, err := c.Compress(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if want := "c7277a0dc1fb853b5f4d9cbd26be40c6"; string(got) != want {
+		t.Errorf("Compress() = %s, want %s", got, want)
+	}
+
+	if _, err := c.Compress(strings.NewReader("6bc1bee22e409f96e93d7e117393172a")); !errors.Is(err, shecomp.ErrBadGranularity) {
+		t.Errorf("expected ErrBadGranularity, got %v", err)
+	}
+}
+
+func TestCompressorBlockValidator(t *testing.T) {
+	errReserved := errors.New("reserved block")
+	var padded []bool
+	c := shecomp.NewCompressor(shecomp.WithBlockValidator(func(index int, block []byte, p bool) error {
+		padded = append(padded, p)
+		if index == 1 && block[0] == 0xff {
+			return errReserved
+		}
+		return nil
+	}))
+
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	if _, err := c.Compress(strings.NewReader(s)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// the last block is the padding only
+	if want := []bool{false, false, true}; !reflect.DeepEqual(padded, want) {
+		t.Errorf("validator called with padded = %v, want %v", padded, want)
+	}
+
+	padded = nil
+	if _, err := c.Compress(strings.NewReader(s[:32] + "ff" + s[34:])); !errors.Is(err, errReserved) {
+		t.Errorf("expected the error of the validator, got %v", err)
+	}
+	if len(padded) != 2 {
+		t.Errorf("validator called %d times, want 2", len(padded))
+	}
+}
+
+func TestCompressorBlockWhitening(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	standard, _ := shecomp.Compress(strings.NewReader(s))
+
+	got, err := shecomp.NewCompressor(shecomp.WithBlockWhitening([16]byte{})).Compress(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(standard, got) {
+		t.Errorf("zero whitening: Compress() = %s, want %s", got, standard)
+	}
+
+	whiten := [16]byte{0xa5, 0x5a}
+	c := shecomp.NewCompressor(shecomp.WithBlockWhitening(whiten))
+	got, _ = c.Compress(strings.NewReader(s))
+	if reflect.DeepEqual(standard, got) {
+		t.Error("non-zero whitening must change the digest")
+	}
+	again, _ := c.Compress(strings.NewReader(s))
+	if !reflect.DeepEqual(again, got) {
+		t.Errorf("whitening is not deterministic: %s and %s", got, again)
+	}
+
+	// whitening the message blocks and the padding by hand gives the same digest
+	raw, _ := hex.DecodeString(s + "80000000000000000000000000000100")
+	for i := range raw {
+		raw[i] ^= whiten[i%16]
+	}
+	want, _ := shecomp.CompressWithoutPadding(strings.NewReader(hex.EncodeToString(raw)))
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Compress() = %s, want %s", got, want)
+	}
+
+	// Write and Sum are whitened consistently
+	raw, _ = hex.DecodeString(s)
+	c.Write(raw)
+	if sum := hex.EncodeToString(c.Sum(nil)); sum != string(got) {
+		t.Errorf("Sum() = %s, want %s", sum, got)
+	}
+}
+
+func TestCompressorForbidZeroBlock(t *testing.T) {
+	c := shecomp.NewCompressor(shecomp.WithForbidZeroBlock(true))
+	zero := strings.Repeat("00", 16)
+	block := "6bc1bee22e409f96e93d7e117393172a"
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"no zero block", block + block, false},
+		{"zero block", block + zero + block, true},
+		// the zero bytes around the padding are not a zero block
+		{"zero tail", block + strings.Repeat("00", 10), false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		_, err := c.Compress(strings.NewReader(tt.input))
+		if tt.wantErr != errors.Is(err, shecomp.ErrZeroBlock) {
+			t.Errorf("%s: got error %v, want ErrZeroBlock = %v", tt.name, err, tt.wantErr)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+
+	if _, err := shecomp.NewCompressor(shecomp.WithForbidZeroBlock(false)).Compress(strings.NewReader(zero)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCompressorWithoutExtraPaddingBlock(t *testing.T) {
+	s := strings.Repeat("88", 32)
+	standard := "27e0d2c61a689a2f3301fa46bf897f8b"
+
+	got, err := shecomp.Compress(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if string(got) != standard {
+		t.Errorf("Compress() = %s, want %s", got, standard)
+	}
+
+	c := shecomp.NewCompressor(shecomp.WithoutExtraPaddingBlock(true), shecomp.WithStrict())
+	got, err = c.Compress(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	// same as CompressWithoutPadding
+	if want := "ac05f063fc163102f5d835a66e56a837"; string(got) != want {
+		t.Errorf("WithoutExtraPaddingBlock: Compress() = %s, want %s", got, want)
+	}
+	if pad, _ := c.Padding(strings.NewReader(s)); len(pad) != 0 {
+		t.Errorf("WithoutExtraPaddingBlock: Padding() = %s, want empty", pad)
+	}
+
+	// the other messages are padded as usual
+	for _, in := range []string{"", s[:40]} {
+		got, _ := c.Compress(strings.NewReader(in))
+		want, _ := shecomp.Compress(strings.NewReader(in))
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%q: Compress() = %s, want %s", in, got, want)
+		}
+	}
+
+	// Write and Sum follow the option too
+	for _, in := range []string{"", s[:40], s} {
+		raw, _ := hex.DecodeString(in)
+		for _, omit := range []bool{false, true} {
+			c := shecomp.NewCompressor(shecomp.WithoutExtraPaddingBlock(omit))
+			want, _ := c.Compress(strings.NewReader(in))
+			c.Write(raw)
+			if got := hex.EncodeToString(c.Sum(nil)); got != string(want) {
+				t.Errorf("%q, omit %v: Sum() = %s, want %s", in, omit, got, want)
+			}
+		}
+	}
+}
+
+func TestNewHash(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	raw, _ := hex.DecodeString(s)
+	want, _ := shecomp.CompressRaw(strings.NewReader(s))
+
+	h := shecomp.New()
+	if h.Size() != 16 || h.BlockSize() != 16 {
+		t.Errorf("Size() = %d, BlockSize() = %d, want 16 and 16", h.Size(), h.BlockSize())
+	}
+	for _, sizes := range [][]int{{1}, {5, 7}, {15, 17}, {33}} {
+		h.Reset()
+		w := io.MultiWriter(h)
+		for p, i := raw, 0; len(p) > 0; i++ {
+			n := sizes[i%len(sizes)]
+			if n > len(p) {
+				n = len(p)
+			}
+			if _, err := w.Write(p[:n]); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			p = p[n:]
+		}
+		// Sum appends the digest to the given slice
+		got := h.Sum([]byte("prefix"))
+		if !bytes.Equal(append([]byte("prefix"), want...), got) {
+			t.Errorf("chunks %v: Sum() = %x, want prefix followed by %x", sizes, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package shecomp
+
+import (
+	"crypto/aes"
+	"testing"
+)
+
+func TestEncryptBlock(t *testing.T) {
+	var key, src [blockSize]byte
+	for i := 0; i < 64; i++ {
+		for j := range key {
+			key[j] = byte(i*7 + j*13)
+			src[j] = byte(i*29 + j*3)
+		}
+		var rk [11 * blockSize]byte
+		var got [blockSize]byte
+		expandKey(&rk, &key)
+		encryptBlock(&rk, &got, &src)
+
+		c, _ := aes.NewCipher(key[:])
+		var want [blockSize]byte
+		c.Encrypt(want[:], src[:])
+		if got != want {
+			t.Errorf("key %x: encryptBlock() = %x, want %x", key, got, want)
+		}
+	}
+}
+
+func TestSubByte(t *testing.T) {
+	for i := range sbox {
+		if got := subByte(byte(i)); got != sbox[i] {
+			t.Errorf("subByte(%#02x) = %#02x, want %#02x", i, got, sbox[i])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package shecomp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CompressDecimalBytes compresses the message written as the whitespace separated decimal values of the bytes,
+// such as "107 193 190", with padding, and returns the raw digest.
+// Each value must be from 0 to 255; otherwise it returns ErrInvalidParameter with the offending token.
+// The input is read as a stream, so it may be split anywhere, even in the middle of a value.
+func CompressDecimalBytes(r io.Reader) ([blockSize]byte, error) {
+	var c Compressor
+	var digest [blockSize]byte
+	sc := bufio.NewScanner(r)
+	sc.Split(bufio.ScanWords)
+
+	buf := make([]byte, 0, 4096)
+	for i := 0; sc.Scan(); i++ {
+		v, err := strconv.ParseUint(sc.Text(), 10, 8)
+		if err != nil {
+			return digest, fmt.Errorf("%w: the token %d %q is not a decimal byte value", ErrInvalidParameter, i, sc.Text())
+		}
+		buf = append(buf, byte(v))
+		if len(buf) == cap(buf) {
+			if _, err := c.Write(buf); err != nil {
+				return digest, err
+			}
+			buf = buf[:0]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return digest, fmt.Errorf("could not read from reader: %w", err)
+	}
+	if _, err := c.Write(buf); err != nil {
+		return digest, err
+	}
+	copy(digest[:], c.Sum(nil))
+	return digest, nil
+}
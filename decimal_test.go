@@ -0,0 +1,39 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressDecimalBytes(t *testing.T) {
+	raw, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	tokens := make([]string, len(raw))
+	for i, b := range raw {
+		tokens[i] = fmt.Sprint(b)
+	}
+	// "107 193 190 ...", wrapped at some lines
+	s := strings.Join(tokens[:20], " ") + "\n" + strings.Join(tokens[20:], "\t")
+
+	// the one byte reader splits every value
+	got, err := shecomp.CompressDecimalBytes(iotest.OneByteReader(strings.NewReader(s)))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if got != specDigest() {
+		t.Errorf("CompressDecimalBytes() = %x, want %x", got, specDigest())
+	}
+
+	for _, bad := range []string{"107 256 190", "107 -1", "107 0x6b"} {
+		_, err := shecomp.CompressDecimalBytes(strings.NewReader(bad))
+		if !errors.Is(err, shecomp.ErrInvalidParameter) {
+			t.Errorf("%q: expected ErrInvalidParameter, got %v", bad, err)
+		}
+	}
+}
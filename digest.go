@@ -0,0 +1,90 @@
+package shecomp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrPrefixMismatch is returned by CompressExpectPrefix when the digest does not start with the expected prefix.
+var ErrPrefixMismatch = errors.New("the digest does not match the expected prefix")
+
+// DigestHalves splits the 128 bits digest into two 64 bits words in big-endian:
+// hi is the first 8 bytes and lo is the last 8 bytes.
+func DigestHalves(digest [blockSize]byte) (hi, lo uint64) {
+	return binary.BigEndian.Uint64(digest[:8]), binary.BigEndian.Uint64(digest[8:])
+}
+
+// HalvesToDigest is the inverse of DigestHalves.
+func HalvesToDigest(hi, lo uint64) [blockSize]byte {
+	var digest [blockSize]byte
+	binary.BigEndian.PutUint64(digest[:8], hi)
+	binary.BigEndian.PutUint64(digest[8:], lo)
+	return digest
+}
+
+// CompressBase64URL compresses the input data same as Compress,
+// and returns the digest in the unpadded base64url encoding, which is more compact than hexadecimal in URLs.
+func CompressBase64URL(r io.Reader) (string, error) {
+	d, err := CompressRaw(r)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(d), nil
+}
+
+// CompressUUID compresses the input data same as Compress, and returns the digest as a version 8 (custom) UUID
+// defined in RFC 9562, the successor of RFC 4122.
+// Only 6 bits of the digest are overwritten: the high nibble of the 7th byte is set to the version 8,
+// and the top 2 bits of the 9th byte are set to the variant 10.
+// The other 122 bits are same as the digest.
+func CompressUUID(r io.Reader) (uuid [blockSize]byte, err error) {
+	d, err := CompressRaw(r)
+	if err != nil {
+		return uuid, err
+	}
+	copy(uuid[:], d)
+	uuid[6] = uuid[6]&0x0f | 0x80
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return uuid, nil
+}
+
+// CompressExpectPrefix compresses the input data same as Compress, and returns ErrPrefixMismatch
+// if the raw digest does not start with prefix, such as a short digest announced by a server.
+// The prefix must be from 1 to 16 bytes; otherwise it returns ErrInvalidParameter.
+// The output is encoded in hexadecimal same as Compress.
+func CompressExpectPrefix(r io.Reader, prefix []byte) ([]byte, error) {
+	if len(prefix) < 1 || len(prefix) > blockSize {
+		return nil, fmt.Errorf("%w: the length of the prefix must be from 1 to %d bytes, but %d", ErrInvalidParameter, blockSize, len(prefix))
+	}
+	d, err := CompressRaw(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(d, prefix) {
+		return nil, fmt.Errorf("%w: got %x, want prefix %x", ErrPrefixMismatch, d, prefix)
+	}
+	return encodeHex(d), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// CompressColonHex compresses the input data same as Compress,
+// and returns the digest as the colon separated hexadecimal bytes like "c7:27:7a:...".
+func CompressColonHex(r io.Reader) (string, error) {
+	d, err := CompressRaw(r)
+	if err != nil {
+		return "", err
+	}
+	h := make([]byte, 0, 3*len(d)-1)
+	for i, b := range d {
+		if i > 0 {
+			h = append(h, ':')
+		}
+		h = append(h, hexDigits[b>>4], hexDigits[b&0xf])
+	}
+	return string(h), nil
+}
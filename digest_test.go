@@ -0,0 +1,115 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func specDigest() [16]byte {
+	var d [16]byte
+	hex.Decode(d[:], []byte("c7277a0dc1fb853b5f4d9cbd26be40c6"))
+	return d
+}
+
+func TestDigestHalves(t *testing.T) {
+	d := specDigest()
+	hi, lo := shecomp.DigestHalves(d)
+	if hi != 0xc7277a0dc1fb853b || lo != 0x5f4d9cbd26be40c6 {
+		t.Errorf("DigestHalves() = (%016x, %016x)", hi, lo)
+	}
+	if got := shecomp.HalvesToDigest(hi, lo); got != d {
+		t.Errorf("HalvesToDigest() = %x, want %x", got, d)
+	}
+}
+
+func TestCompressBase64URL(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	got, err := shecomp.CompressBase64URL(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want := "xyd6DcH7hTtfTZy9Jr5Axg"; got != want {
+		t.Errorf("CompressBase64URL() = %s, want %s", got, want)
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(got)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	raw, err := shecomp.CompressRaw(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !bytes.Equal(decoded, raw) {
+		t.Errorf("decoded %x, want %x", decoded, raw)
+	}
+}
+
+func TestCompressUUID(t *testing.T) {
+	got, err := shecomp.CompressUUID(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if v := got[6] >> 4; v != 8 {
+		t.Errorf("version = %d, want 8", v)
+	}
+	if v := got[8] >> 6; v != 0b10 {
+		t.Errorf("variant = %02b, want 10", v)
+	}
+
+	d := specDigest()
+	d[6] = d[6]&0x0f | got[6]&0xf0
+	d[8] = d[8]&0x3f | got[8]&0xc0
+	if got != d {
+		t.Errorf("CompressUUID() = %x, want the digest except the version and the variant: %x", got, d)
+	}
+}
+
+func TestCompressExpectPrefix(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	d := specDigest()
+
+	for _, n := range []int{1, 4, 16} {
+		got, err := shecomp.CompressExpectPrefix(strings.NewReader(s), d[:n])
+		if err != nil {
+			t.Errorf("%d bytes prefix: unexpected error: %v", n, err)
+			continue
+		}
+		if string(got) != hex.EncodeToString(d[:]) {
+			t.Errorf("%d bytes prefix: CompressExpectPrefix() = %s, want %x", n, got, d)
+		}
+
+		wrong := bytes.Clone(d[:n])
+		wrong[n-1] ^= 1
+		if _, err := shecomp.CompressExpectPrefix(strings.NewReader(s), wrong); !errors.Is(err, shecomp.ErrPrefixMismatch) {
+			t.Errorf("%d bytes prefix: expected ErrPrefixMismatch, got %v", n, err)
+		}
+	}
+
+	for _, prefix := range [][]byte{nil, make([]byte, 17)} {
+		if _, err := shecomp.CompressExpectPrefix(strings.NewReader(s), prefix); !errors.Is(err, shecomp.ErrInvalidParameter) {
+			t.Errorf("%d bytes prefix: expected ErrInvalidParameter, got %v", len(prefix), err)
+		}
+	}
+}
+
+func TestCompressColonHex(t *testing.T) {
+	got, err := shecomp.CompressColonHex(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want := "c7:27:7a:0d:c1:fb:85:3b:5f:4d:9c:bd:26:be:40:c6"; got != want {
+		t.Errorf("CompressColonHex() = %s, want %s", got, want)
+	}
+}
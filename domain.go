@@ -0,0 +1,48 @@
+package shecomp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CompressWithDomain compresses the raw bytes of the domain tag followed by the raw bytes read from r,
+// and returns the raw digest.
+// The domain and the message are absorbed in this order as a single message,
+// and the padding is added once at the end with the length of domain + message,
+// so that the digest is same as CompressText(domain + message).
+// Unlike Compress, r is read as raw binary, not hexadecimal encoded text.
+// Note that the domain tag is not delimited: the caller should use tags which are not a prefix of each other.
+func CompressWithDomain(domain string, r io.Reader) ([blockSize]byte, error) {
+	return compressPrefixed([]byte(domain), r)
+}
+
+// CompressCounter compresses the 128 bits counter block followed by the raw bytes read from r,
+// and returns the raw digest.
+// The counter block is the counter in 128 bits big-endian, that is, 8 bytes of zero followed by
+// the counter in 64 bits big-endian. The counter block and the message are absorbed as a single message
+// with the padding added once at the end, same as CompressWithDomain.
+func CompressCounter(counter uint64, r io.Reader) ([blockSize]byte, error) {
+	var block [blockSize]byte
+	binary.BigEndian.PutUint64(block[8:], counter)
+	return compressPrefixed(block[:], r)
+}
+
+// compressPrefixed compresses prefix followed by the raw bytes read from r with padding.
+func compressPrefixed(prefix []byte, r io.Reader) ([blockSize]byte, error) {
+	var c Compressor
+	var digest [blockSize]byte
+	if _, err := c.Write(prefix); err != nil {
+		return digest, err
+	}
+	if _, err := io.Copy(&c, r); err != nil {
+		// the error of Write is not about the reader
+		if errors.Is(err, ErrLargePlainText) {
+			return digest, err
+		}
+		return digest, fmt.Errorf("could not read from reader: %w", err)
+	}
+	copy(digest[:], c.Sum(nil))
+	return digest, nil
+}
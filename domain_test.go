@@ -0,0 +1,55 @@
+package shecomp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressWithDomain(t *testing.T) {
+	msg := "firmware image v1.2.3"
+
+	boot, err := shecomp.CompressWithDomain("SHE-BOOT:", strings.NewReader(msg))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	app, err := shecomp.CompressWithDomain("SHE-APP:", strings.NewReader(msg))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if boot == app {
+		t.Errorf("different domains must produce different digests, got %x for both", boot)
+	}
+
+	// the padding covers the domain and the message together
+	want, _ := shecomp.CompressText("SHE-BOOT:" + msg)
+	if boot != want {
+		t.Errorf("CompressWithDomain() = %x, want %x", boot, want)
+	}
+}
+
+func TestCompressCounter(t *testing.T) {
+	msg := "seed"
+	seen := make(map[[16]byte]uint64)
+	for counter := uint64(0); counter < 4; counter++ {
+		got, err := shecomp.CompressCounter(counter, strings.NewReader(msg))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if prev, ok := seen[got]; ok {
+			t.Errorf("counters %d and %d produce the same digest %x", prev, counter, got)
+		}
+		seen[got] = counter
+	}
+
+	// the counter block is 128 bits big-endian
+	got, _ := shecomp.CompressCounter(0x0102, strings.NewReader(msg))
+	want, _ := shecomp.CompressText(strings.Repeat("\x00", 14) + "\x01\x02" + msg)
+	if got != want {
+		t.Errorf("CompressCounter() = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package shecomp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// InputEncoding selects how CompressWithEncoding reads the input.
+type InputEncoding int
+
+const (
+	// EncodingAuto detects the encoding from the beginning of the input, see CompressAutoEncoding.
+	EncodingAuto InputEncoding = iota
+	// EncodingHex reads the input as hexadecimal encoded text, same as Compress.
+	EncodingHex
+	// EncodingBinary reads the input as raw binary, same as a Compressor with WithBinaryInput.
+	EncodingBinary
+)
+
+// autoDetectLen is the number of the bytes which CompressAutoEncoding inspects.
+const autoDetectLen = 64
+
+// CompressAutoEncoding compresses the input data with padding, reading it as hexadecimal encoded text
+// if the first 64 bytes (or the whole input if shorter) are all hexadecimal digits, and as raw binary otherwise.
+// The output is encoded in hexadecimal.
+//
+// The trailing whitespaces of the inspected bytes, such as the newline at the end of a text file, are ignored
+// for the detection, and the whitespaces in the input read as hexadecimal text are skipped same as
+// WithWhitespaceTolerance, so "abcd\n" is compressed same as "abcd".
+//
+// The detection is a heuristic: a binary input which happens to start with 64 ASCII hexadecimal digits
+// is read as hexadecimal text, and a hexadecimal text with a whitespace in the beginning is read as binary.
+// When the encoding is known, force it by CompressWithEncoding with EncodingHex or EncodingBinary.
+func CompressAutoEncoding(r io.Reader) ([]byte, error) {
+	return CompressWithEncoding(r, EncodingAuto)
+}
+
+// CompressWithEncoding compresses the input data with padding, reading it in the encoding e.
+// The output is encoded in hexadecimal.
+func CompressWithEncoding(r io.Reader, e InputEncoding) ([]byte, error) {
+	switch e {
+	case EncodingAuto:
+		br := bufio.NewReader(r)
+		head, err := br.Peek(autoDetectLen)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("could not read from reader: %w", err)
+		}
+		text := bytes.TrimRight(head, " \t\r\n")
+		e = EncodingHex
+		for _, c := range text {
+			if !isHexDigit(c) {
+				e = EncodingBinary
+				break
+			}
+		}
+		if e == EncodingHex {
+			return NewCompressor(WithWhitespaceTolerance()).Compress(br)
+		}
+		return CompressWithEncoding(br, e)
+	case EncodingHex:
+		return Compress(r)
+	case EncodingBinary:
+		return NewCompressor(WithBinaryInput()).Compress(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown input encoding %d", ErrInvalidParameter, e)
+	}
+}
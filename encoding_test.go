@@ -0,0 +1,53 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressAutoEncoding(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	raw, _ := hex.DecodeString(s)
+	want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")
+
+	long := strings.Repeat(s, 4)
+	wantLong, _ := shecomp.Compress(strings.NewReader(long))
+	wantShort, _ := shecomp.Compress(strings.NewReader("abcd"))
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  []byte
+	}{
+		{"hex", []byte(s), want},
+		{"hex longer than the peek", []byte(long), wantLong},
+		{"binary", raw, want},
+		{"hex with a trailing newline", []byte(s + "\n"), want},
+		{"short hex with a trailing newline", []byte("abcd\r\n"), wantShort},
+	}
+	for _, tt := range tests {
+		// the peek must not lose the bytes read in small pieces
+		got, err := shecomp.CompressAutoEncoding(iotest.HalfReader(bytes.NewReader(tt.input)))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%s: CompressAutoEncoding() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// the ASCII of hexadecimal digits is ambiguous, so the encoding can be forced
+	ascii := []byte("0123456789abcdef")
+	got, _ := shecomp.CompressWithEncoding(bytes.NewReader(ascii), shecomp.EncodingBinary)
+	wantASCII, _ := shecomp.Compress(strings.NewReader(hex.EncodeToString(ascii)))
+	if !reflect.DeepEqual(wantASCII, got) {
+		t.Errorf("CompressWithEncoding(EncodingBinary) = %s, want %s", got, wantASCII)
+	}
+}
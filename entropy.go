@@ -0,0 +1,53 @@
+package shecomp
+
+import (
+	"math"
+	"math/bits"
+)
+
+// CompressWithEntropy compresses the raw data with padding, and returns the raw digest
+// together with the Shannon entropy of the byte frequency of the data in bits per byte, from 0 to 8.
+// Both are computed in a single pass over the data.
+// A low entropy may indicate a bad key source, such as a zero filled or repeated pattern.
+// The entropy of empty data is 0.
+func CompressWithEntropy(data []byte) (digest [blockSize]byte, entropyBitsPerByte float64, err error) {
+	var c Compressor
+	var hist [256]int
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > 4096 {
+			chunk = chunk[:4096]
+		}
+		for _, b := range chunk {
+			hist[b]++
+		}
+		if _, err := c.Write(chunk); err != nil {
+			return digest, 0, err
+		}
+		data = data[len(chunk):]
+	}
+	copy(digest[:], c.Sum(nil))
+
+	total := float64(c.n)
+	for _, k := range hist {
+		if k == 0 {
+			continue
+		}
+		p := float64(k) / total
+		entropyBitsPerByte -= p * math.Log2(p)
+	}
+	return digest, entropyBitsPerByte, nil
+}
+
+// CompressWithHammingWeight compresses the raw data with padding, and returns the raw digest
+// together with the number of the set bits in it, which is around 64 for a healthy digest.
+func CompressWithHammingWeight(data []byte) (digest [blockSize]byte, popcount int, err error) {
+	digest, err = compressBytes(data)
+	if err != nil {
+		return digest, 0, err
+	}
+	for _, b := range digest {
+		popcount += bits.OnesCount8(b)
+	}
+	return digest, popcount, nil
+}
@@ -0,0 +1,69 @@
+package shecomp_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressWithEntropy(t *testing.T) {
+	uniform := make([]byte, 256*16)
+	for i := range uniform {
+		uniform[i] = byte(i)
+	}
+	random := make([]byte, 1<<16)
+	rand.Read(random)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    float64
+		epsilon float64
+	}{
+		{"all zeros", make([]byte, 64), 0, 0},
+		{"empty", nil, 0, 0},
+		{"every byte value equally", uniform, 8, 1e-9},
+		{"uniform random", random, 8, 0.01},
+	}
+	for _, tt := range tests {
+		digest, entropy, err := shecomp.CompressWithEntropy(tt.data)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(entropy-tt.want) > tt.epsilon {
+			t.Errorf("%s: entropy = %v, want %v", tt.name, entropy, tt.want)
+		}
+		want, _ := shecomp.CompressText(string(tt.data))
+		if digest != want {
+			t.Errorf("%s: digest = %x, want %x", tt.name, digest, want)
+		}
+	}
+}
+
+func TestCompressWithHammingWeight(t *testing.T) {
+	data, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	digest, popcount, err := shecomp.CompressWithHammingWeight(data)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if digest != specDigest() {
+		t.Errorf("digest = %x, want %x", digest, specDigest())
+	}
+
+	want := 0
+	for _, c := range "c7277a0dc1fb853b5f4d9cbd26be40c6" {
+		v, _ := strconv.ParseUint(string(c), 16, 8)
+		for ; v > 0; v >>= 1 {
+			want += int(v & 1)
+		}
+	}
+	if popcount != want {
+		t.Errorf("popcount = %d, want %d", popcount, want)
+	}
+}
@@ -0,0 +1,34 @@
+package shecomp
+
+import "crypto/subtle"
+
+// Equal reports whether the hexadecimal encoded digests a and b are same, in constant time.
+// The comparison is case-insensitive, so an expected digest in upper case matches the output of Compress.
+// It returns false if the lengths differ; the length is not regarded as secret.
+// It returns false if a or b has a byte which is not a hexadecimal digit, even if the other has the same byte.
+func Equal(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	fa, fb := make([]byte, len(a)), make([]byte, len(b))
+	valid := 1
+	for i := range a {
+		var ok int
+		fa[i], ok = foldHex(a[i])
+		valid &= ok
+		fb[i], ok = foldHex(b[i])
+		valid &= ok
+	}
+	return subtle.ConstantTimeCompare(fa, fb)&valid == 1
+}
+
+// foldHex folds A-F into a-f in constant time, keeping the other bytes as they are,
+// and reports 1 if c is a hexadecimal digit, 0 otherwise.
+func foldHex(c byte) (byte, int) {
+	x := int(c)
+	upper := subtle.ConstantTimeLessOrEq('A', x) & subtle.ConstantTimeLessOrEq(x, 'F')
+	x |= upper << 5
+	digit := subtle.ConstantTimeLessOrEq('0', x) & subtle.ConstantTimeLessOrEq(x, '9')
+	lower := subtle.ConstantTimeLessOrEq('a', x) & subtle.ConstantTimeLessOrEq(x, 'f')
+	return byte(x), digit | lower
+}
@@ -0,0 +1,40 @@
+package shecomp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestEqual(t *testing.T) {
+	digest, _ := shecomp.Compress(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+
+	tests := []struct {
+		name string
+		b    string
+		want bool
+	}{
+		{"same", "c7277a0dc1fb853b5f4d9cbd26be40c6", true},
+		{"upper case", "C7277A0DC1FB853B5F4D9CBD26BE40C6", true},
+		{"last digit differs", "c7277a0dc1fb853b5f4d9cbd26be40c7", false},
+		{"first digit differs", "07277a0dc1fb853b5f4d9cbd26be40c6", false},
+		{"shorter", "c7277a0dc1fb853b5f4d9cbd26be40", false},
+		{"longer", "c7277a0dc1fb853b5f4d9cbd26be40c600", false},
+		{"empty", "", false},
+		{"non-hex byte folding to a digit", "c\x17277a0dc1fb853b5f4d9cbd26be40c6", false},
+	}
+
+	for _, tt := range tests {
+		if got := shecomp.Equal(digest, []byte(tt.b)); got != tt.want {
+			t.Errorf("%s: Equal() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// a non-hex byte never matches, even the same byte or the other case of it
+	for _, tt := range [][2]string{{"0g", "0g"}, {"0g", "0G"}, {"  ", "  "}} {
+		if shecomp.Equal([]byte(tt[0]), []byte(tt[1])) {
+			t.Errorf("Equal(%q, %q) = true, want false", tt[0], tt[1])
+		}
+	}
+}
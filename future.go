@@ -0,0 +1,44 @@
+package shecomp
+
+import (
+	"context"
+	"io"
+)
+
+// Future is the pending result of CompressAsync.
+type Future struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	out    []byte
+	err    error
+}
+
+// CompressAsync starts compressing the input data same as Compress on a new goroutine, and returns its Future.
+// The caller must not use r until Wait returns.
+func CompressAsync(r io.Reader) *Future {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Future{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(f.done)
+		defer cancel()
+		f.out, f.err = CompressContext(ctx, r)
+	}()
+	return f
+}
+
+// Wait blocks until the compression finishes, and returns its result same as Compress.
+// It may be called multiple times and from multiple goroutines.
+func (f *Future) Wait() ([]byte, error) {
+	<-f.done
+	return f.out, f.err
+}
+
+// Cancel stops the compression same as canceling the context of CompressContext,
+// then Wait returns the error wrapping context.Canceled.
+// It has no effect if the compression has already finished.
+func (f *Future) Cancel() {
+	f.cancel()
+}
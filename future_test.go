@@ -0,0 +1,53 @@
+package shecomp_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressAsync(t *testing.T) {
+	inputs := []string{
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+		"",
+		"0123456789abcdef",
+	}
+	futures := make([]*shecomp.Future, len(inputs))
+	for i, s := range inputs {
+		futures[i] = shecomp.CompressAsync(strings.NewReader(s))
+	}
+
+	var wg sync.WaitGroup
+	for i, f := range futures {
+		want, _ := shecomp.Compress(strings.NewReader(inputs[i]))
+		// Wait can be called from multiple goroutines
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(f *shecomp.Future) {
+				defer wg.Done()
+				got, err := f.Wait()
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("Wait() = %s, want %s", got, want)
+				}
+			}(f)
+		}
+	}
+	wg.Wait()
+}
+
+func TestCompressAsyncCancel(t *testing.T) {
+	f := shecomp.CompressAsync(infiniteReader{})
+	f.Cancel()
+	if _, err := f.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
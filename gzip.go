@@ -0,0 +1,37 @@
+package shecomp
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidGzip is returned by CompressGzip when the input is not a valid gzip stream.
+var ErrInvalidGzip = errors.New("invalid gzip stream")
+
+// CompressGzip decompresses the gzip stream read from r, and compresses the decompressed raw binary with padding.
+// The output is encoded in hexadecimal same as Compress.
+// The errors of the gzip format, including the checksum mismatch at the end of the stream, wrap ErrInvalidGzip
+// in addition to the original error, so that they can be told apart from the errors of the compression.
+func CompressGzip(r io.Reader) ([]byte, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGzip, err)
+	}
+	defer zr.Close()
+	return NewCompressor(WithBinaryInput()).Compress(&gzipReader{zr})
+}
+
+// gzipReader wraps the errors of the gzip reader with ErrInvalidGzip.
+type gzipReader struct {
+	r *gzip.Reader
+}
+
+func (r *gzipReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("%w: %w", ErrInvalidGzip, err)
+	}
+	return n, err
+}
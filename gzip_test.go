@@ -0,0 +1,41 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressGzip(t *testing.T) {
+	raw, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(raw)
+	zw.Close()
+	gz := buf.Bytes()
+
+	got, err := shecomp.CompressGzip(bytes.NewReader(gz))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6"); !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressGzip() = %s, want %s", got, want)
+	}
+
+	if _, err := shecomp.CompressGzip(bytes.NewReader(raw)); !errors.Is(err, shecomp.ErrInvalidGzip) {
+		t.Errorf("expected ErrInvalidGzip for a non gzip input, got %v", err)
+	}
+
+	// corrupt the CRC-32 in the trailer
+	corrupted := bytes.Clone(gz)
+	corrupted[len(corrupted)-8] ^= 0xff
+	if _, err := shecomp.CompressGzip(bytes.NewReader(corrupted)); !errors.Is(err, shecomp.ErrInvalidGzip) {
+		t.Errorf("expected ErrInvalidGzip for a corrupted stream, got %v", err)
+	}
+}
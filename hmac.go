@@ -0,0 +1,29 @@
+package shecomp
+
+import "crypto/hmac"
+
+// HMAC calculates HMAC (RFC 2104) of the raw message with the SHE compression as the hash function, and returns the raw MAC.
+// Since the block size of the compression is 16 bytes, a key longer than 16 bytes is compressed first.
+// This is not a part of SHE specification; for the MAC of SHE, use CMAC as in M5.
+func HMAC(key, message []byte) ([blockSize]byte, error) {
+	var mac [blockSize]byte
+	h := hmac.New(New, key)
+	if _, err := h.Write(message); err != nil {
+		return mac, err
+	}
+	copy(mac[:], h.Sum(nil))
+	return mac, nil
+}
+
+// CompressAndHMAC compresses the raw message with padding same as CompressText, and calculates HMAC of the digest
+// with hmacKey, so that the digest can be sent over an untrusted channel together with the tag.
+// The recipient should verify the tag by HMAC(hmacKey, digest) and hmac.Equal before trusting the digest.
+// hmacKey should be independent of any key derived from the message.
+func CompressAndHMAC(message, hmacKey []byte) (digest, tag [blockSize]byte, err error) {
+	digest, err = compressBytes(message)
+	if err != nil {
+		return digest, tag, err
+	}
+	tag, err = HMAC(hmacKey, digest[:])
+	return digest, tag, err
+}
@@ -0,0 +1,80 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/hex"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+// manualHMAC computes H((K' ^ opad) || H((K' ^ ipad) || message)) with the hash.Hash of the package.
+func manualHMAC(key, message []byte) []byte {
+	if len(key) > 16 {
+		h := shecomp.New()
+		h.Write(key)
+		key = h.Sum(nil)
+	}
+	ipad := make([]byte, 16)
+	opad := make([]byte, 16)
+	copy(ipad, key)
+	copy(opad, key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+
+	inner := shecomp.New()
+	inner.Write(ipad)
+	inner.Write(message)
+	outer := shecomp.New()
+	outer.Write(opad)
+	outer.Write(inner.Sum(nil))
+	return outer.Sum(nil)
+}
+
+func TestHMAC(t *testing.T) {
+	message := []byte("the message to be authenticated")
+	for _, key := range [][]byte{
+		nil,
+		[]byte("short key"),
+		[]byte("0123456789abcdef"),
+		[]byte("a key longer than the block size"),
+	} {
+		got, err := shecomp.HMAC(key, message)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if want := manualHMAC(key, message); !bytes.Equal(got[:], want) {
+			t.Errorf("HMAC(%q) = %x, want %x", key, got, want)
+		}
+	}
+}
+
+func TestCompressAndHMAC(t *testing.T) {
+	message, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	key := []byte("transport key")
+
+	digest, tag, err := shecomp.CompressAndHMAC(message, key)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if digest != specDigest() {
+		t.Errorf("digest = %x, want %x", digest, specDigest())
+	}
+
+	verify := func(digest, tag [16]byte) bool {
+		want, err := shecomp.HMAC(key, digest[:])
+		return err == nil && hmac.Equal(want[:], tag[:])
+	}
+	if !verify(digest, tag) {
+		t.Error("the tag does not verify")
+	}
+	digest[0] ^= 1
+	if verify(digest, tag) {
+		t.Error("the tag verifies a tampered digest")
+	}
+}
@@ -0,0 +1,63 @@
+package shecomp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvariantViolated is returned when an internal invariant, such as the length of a block, does not hold.
+// It indicates a bug of this package, not a problem of the input.
+var ErrInvariantViolated = errors.New("internal invariant violated")
+
+// invariant returns nil if cond holds.
+// Otherwise it returns an error wrapping ErrInvariantViolated,
+// or panics with the error if built with the shecomp_debug tag.
+func invariant(cond bool, format string, args ...any) error {
+	if cond {
+		return nil
+	}
+	err := fmt.Errorf("%w: %s", ErrInvariantViolated, fmt.Sprintf(format, args...))
+	if debugAssert {
+		panic(err)
+	}
+	return err
+}
+
+// verifyInvariants checks the state after the compression by br finished.
+func verifyInvariants(br BlockReader, out []byte) error {
+	if err := invariant(len(out) == blockSize, "the length of the digest must be %d, but %d", blockSize, len(out)); err != nil {
+		return err
+	}
+	pr, ok := br.(*paddingReader)
+	if !ok {
+		return nil
+	}
+	if pr.noExtra && len(pr.pad) == 0 {
+		return invariant(pr.readBytes > 0 && pr.readBytes%blockSize == 0, "the padding can be omitted only for a non-empty message multiple of block size, but %d bytes", pr.readBytes)
+	}
+	if err := invariant((pr.readBytes+uint64(len(pr.pad)))%blockSize == 0, "the padded message must be multiple of block size, but message %d bytes and padding %d bytes", pr.readBytes, len(pr.pad)); err != nil {
+		return err
+	}
+	want := pr.scheme.padLen(int(pr.readBytes % blockSize))
+	if err := invariant(len(pr.pad) == want, "the length of the padding must be %d, but %d", want, len(pr.pad)); err != nil {
+		return err
+	}
+	if err := invariant(pr.pad[0]&0x80 != 0, "the first bit of the padding must be 1"); err != nil {
+		return err
+	}
+	if pr.scheme != PaddingSHE {
+		return nil
+	}
+	var bits uint64
+	for _, b := range pr.pad[len(pr.pad)-5:] {
+		bits = bits<<8 | uint64(b)
+	}
+	return invariant(bits == pr.readBytes*8&maxBitLength, "the length field of the padding must be %d, but %d", pr.readBytes*8&maxBitLength, bits)
+}
+
+// CompressStrict is same as Compress, but verifies the internal invariants of the compression.
+// See WithStrict.
+func CompressStrict(r io.Reader) ([]byte, error) {
+	return NewCompressor(WithStrict()).Compress(r)
+}
@@ -0,0 +1,40 @@
+package shecomp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncryptInvariant(t *testing.T) {
+	// feed the primitive a wrong-length buffer, which never happens through the public API.
+	call := func() error {
+		_, err := encrypt(make([]byte, blockSize-1), make([]byte, blockSize))
+		return err
+	}
+
+	if debugAssert {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic in the debug build")
+			}
+		}()
+		call()
+		return
+	}
+	if err := call(); !errors.Is(err, ErrInvariantViolated) {
+		t.Errorf("expected ErrInvariantViolated, got %v", err)
+	}
+}
+
+func TestVerifyInvariants(t *testing.T) {
+	if debugAssert {
+		t.Skip("invariant violations panic in the debug build")
+	}
+	br := newPaddingReader(nil)
+	br.readBytes = 3
+	// the padding for 2 bytes tail, which does not match 3 bytes message
+	br.pad = padding(make([]byte, 2), 3)
+	if err := verifyInvariants(br, make([]byte, blockSize)); !errors.Is(err, ErrInvariantViolated) {
+		t.Errorf("expected ErrInvariantViolated, got %v", err)
+	}
+}
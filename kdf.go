@@ -0,0 +1,67 @@
+package shecomp
+
+import "fmt"
+
+// The constants used with the key derivation function, defined in SHE specification.
+// Each of them already contains the SHE padding for the message K || C[:6].
+var (
+	keyUpdateEncC = []byte{0x01, 0x01, 0x53, 0x48, 0x45, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xb0}
+	keyUpdateMacC = []byte{0x01, 0x02, 0x53, 0x48, 0x45, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xb0}
+)
+
+// kdf is the key derivation function KDF(K, C) = AES-MP(K || C) defined in SHE specification.
+// The constant C carries the padding, so no additional padding is applied.
+func kdf(k, c []byte) ([]byte, error) {
+	src := make([]byte, 0, len(k)+len(c))
+	src = append(src, k...)
+	src = append(src, c...)
+	out, err := compress(&sliceReader{src})
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return out, nil
+}
+
+// KDF is the key derivation function KDF(K, C) = AES-MP(K || C) defined in SHE specification,
+// which derives K1 = KDF(K, KEY_UPDATE_ENC_C) and K2 = KDF(K, KEY_UPDATE_MAC_C) in the memory update protocol.
+// k and c are raw bytes, and each of them must be non-empty and multiple of 16 bytes.
+// Following the specification, no padding is added: the constants such as KEY_UPDATE_ENC_C
+// (010153484500800000000000000000b0) already contain the padding of K || C.
+// The output is the raw 16 bytes derived key.
+func KDF(k, c []byte) ([]byte, error) {
+	if len(k) == 0 || len(k)%blockSize != 0 {
+		return nil, fmt.Errorf("%w: the length of K must be multiple of %d bytes, but %d", ErrInvalidParameter, blockSize, len(k))
+	}
+	if len(c) == 0 || len(c)%blockSize != 0 {
+		return nil, fmt.Errorf("%w: the length of C must be multiple of %d bytes, but %d", ErrInvalidParameter, blockSize, len(c))
+	}
+	if uint64(len(k)+len(c))*8 > maxBitLength {
+		return nil, ErrLargePlainText
+	}
+	return kdf(k, c)
+}
+
+// DeriveKeys derives one key per constant from the master key with the SHE key derivation function,
+// KDF(master, C) = AES-MP(master || C), where each constant C must be the 128 bits constant already containing the padding,
+// such as KEY_UPDATE_ENC_C (010153484500800000000000000000b0) and KEY_UPDATE_MAC_C (010253484500800000000000000000b0).
+// All the lengths are validated before any derivation.
+// The output is the raw 16 bytes keys in the order of constants.
+func DeriveKeys(master []byte, constants [][]byte) ([][]byte, error) {
+	if len(master) != keySize {
+		return nil, fmt.Errorf("%w: the length of the master key must be %d bytes, but %d", ErrInvalidParameter, keySize, len(master))
+	}
+	for i, c := range constants {
+		if len(c) != blockSize {
+			return nil, fmt.Errorf("%w: the length of the constant %d must be %d bytes, but %d", ErrInvalidParameter, i, blockSize, len(c))
+		}
+	}
+	keys := make([][]byte, len(constants))
+	for i, c := range constants {
+		k, err := kdf(master, c)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = k
+	}
+	return keys, nil
+}
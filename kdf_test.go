@@ -0,0 +1,74 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestDeriveKeys(t *testing.T) {
+	master, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	enc, _ := hex.DecodeString("010153484500800000000000000000b0")
+	mac, _ := hex.DecodeString("010253484500800000000000000000b0")
+
+	keys, err := shecomp.DeriveKeys(master, [][]byte{enc, mac})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if len(keys) != 2 {
+		t.Errorf("DeriveKeys() returns %d keys, want 2", len(keys))
+		return
+	}
+	// K1 of the example described in SHE specification 4.13.2.10
+	if got := hex.EncodeToString(keys[0]); got != "118a46447a770d87828a69c222e2d17e" {
+		t.Errorf("ENC key = %s, want 118a46447a770d87828a69c222e2d17e", got)
+	}
+	for i, c := range [][]byte{enc, mac} {
+		want, _ := shecomp.CompressWithoutPadding(strings.NewReader(hex.EncodeToString(master) + hex.EncodeToString(c)))
+		if got := hex.EncodeToString(keys[i]); got != string(want) {
+			t.Errorf("key %d = %s, want %s", i, got, want)
+		}
+	}
+
+	if _, err := shecomp.DeriveKeys(master[:15], [][]byte{enc}); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a short master key, got %v", err)
+	}
+	if _, err := shecomp.DeriveKeys(master, [][]byte{enc, mac[:8]}); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a short constant, got %v", err)
+	}
+}
+
+func TestKDF(t *testing.T) {
+	k, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	// K1 and K2 of the example described in SHE specification 4.13.2.10
+	tests := []struct {
+		c    string
+		want string
+	}{
+		{"010153484500800000000000000000b0", "118a46447a770d87828a69c222e2d17e"},
+		{"010253484500800000000000000000b0", "2ebb2a3da62dbd64b18ba6493e9fbe22"},
+	}
+
+	for _, tt := range tests {
+		c, _ := hex.DecodeString(tt.c)
+		got, err := shecomp.KDF(k, c)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("KDF(K, %s) = %x, want %s", tt.c, got, tt.want)
+		}
+	}
+
+	c, _ := hex.DecodeString(tests[0].c)
+	for _, bad := range [][2][]byte{{k[:15], c}, {nil, c}, {k, c[:8]}, {k, nil}} {
+		if _, err := shecomp.KDF(bad[0], bad[1]); !errors.Is(err, shecomp.ErrInvalidParameter) {
+			t.Errorf("KDF(%d bytes, %d bytes): expected ErrInvalidParameter, got %v", len(bad[0]), len(bad[1]), err)
+		}
+	}
+}
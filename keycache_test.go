@@ -0,0 +1,88 @@
+package shecomp_test
+
+import (
+	"crypto/cipher"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+// mapCache is an unbounded KeyScheduleCache counting its hits.
+type mapCache struct {
+	mu     sync.Mutex
+	blocks map[string]cipher.Block
+	hits   int
+}
+
+func (m *mapCache) Get(key []byte) (cipher.Block, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.blocks[string(key)]
+	if ok {
+		m.hits++
+	}
+	return b, ok
+}
+
+func (m *mapCache) Put(key []byte, b cipher.Block) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.blocks == nil {
+		m.blocks = map[string]cipher.Block{}
+	}
+	m.blocks[string(key)] = b
+}
+
+// prefixedMessages returns messages sharing a header of 8 blocks.
+func prefixedMessages() []string {
+	header := strings.Repeat("48", 8*16)
+	return []string{header + "01", header + "02", header + "0303", header + "04"}
+}
+
+func TestKeyScheduleCache(t *testing.T) {
+	cache := &mapCache{}
+	c := shecomp.NewCompressor(shecomp.WithKeyScheduleCache(cache))
+
+	for _, s := range prefixedMessages() {
+		want, err := shecomp.Compress(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		got, err := c.Compress(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("Compress() = %s, want %s", got, want)
+		}
+	}
+	// each message after the first one hits the cache for all blocks of the header
+	if want := 3 * 8; cache.hits < want {
+		t.Errorf("got %d cache hits, want at least %d", cache.hits, want)
+	}
+}
+
+func BenchmarkKeyScheduleCache(b *testing.B) {
+	messages := prefixedMessages()
+	b.Run("without cache", func(b *testing.B) {
+		b.ReportAllocs()
+		c := shecomp.NewCompressor()
+		for i := 0; i < b.N; i++ {
+			c.Compress(strings.NewReader(messages[i%len(messages)]))
+		}
+	})
+	b.Run("with cache", func(b *testing.B) {
+		b.ReportAllocs()
+		cache := &mapCache{}
+		c := shecomp.NewCompressor(shecomp.WithKeyScheduleCache(cache))
+		for i := 0; i < b.N; i++ {
+			c.Compress(strings.NewReader(messages[i%len(messages)]))
+		}
+		b.ReportMetric(float64(cache.hits)/float64(b.N), "hits/op")
+	})
+}
@@ -0,0 +1,117 @@
+package shecomp
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The key slot IDs defined in SHE specification.
+const (
+	SlotSecretKey    = 0x0
+	SlotMasterECUKey = 0x1
+	SlotBootMACKey   = 0x2
+	SlotBootMAC      = 0x3
+	SlotKey1         = 0x4
+	SlotKey2         = 0x5
+	SlotKey3         = 0x6
+	SlotKey4         = 0x7
+	SlotKey5         = 0x8
+	SlotKey6         = 0x9
+	SlotKey7         = 0xa
+	SlotKey8         = 0xb
+	SlotKey9         = 0xc
+	SlotKey10        = 0xd
+	SlotRAMKey       = 0xe
+)
+
+const (
+	keySize    = 16
+	uidSize    = 15
+	maxCounter = 1<<28 - 1
+)
+
+// ErrInvalidParameter is returned when a parameter of the SHE protocol helpers is out of its range.
+var ErrInvalidParameter = errors.New("invalid parameter for the SHE protocol")
+
+// M4 calculates the verification message M4 which an ECU returns after the memory update protocol.
+// M4 = UID | ID | AuthID | ENC_ECB,K3(C_ID | 1 | 0...0), where K3 = KDF(newKey, KEY_UPDATE_ENC_C).
+// newKey is the raw 128 bits key loaded into the slot keyID, uid is the 120 bits UID of the ECU,
+// authID is the slot of the authorizing key, and counter is the 28 bits counter of the new key.
+// The output is the raw 32 bytes M4.
+func M4(newKey, uid []byte, keyID, authID uint8, counter uint32) ([]byte, error) {
+	if len(newKey) != keySize {
+		return nil, fmt.Errorf("%w: the length of the key must be %d bytes, but %d", ErrInvalidParameter, keySize, len(newKey))
+	}
+	if len(uid) != uidSize {
+		return nil, fmt.Errorf("%w: the length of UID must be %d bytes, but %d", ErrInvalidParameter, uidSize, len(uid))
+	}
+	if keyID > 0xf || authID > 0xf {
+		return nil, fmt.Errorf("%w: the key ID and the auth ID must be 4 bits, but %#x and %#x", ErrInvalidParameter, keyID, authID)
+	}
+	if counter > maxCounter {
+		return nil, fmt.Errorf("%w: the counter must be 28 bits, but %#x", ErrInvalidParameter, counter)
+	}
+
+	k3, err := kdf(newKey, keyUpdateEncC)
+	if err != nil {
+		return nil, err
+	}
+	cipher, err := aes.NewCipher(k3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize aes cipher: %w", err)
+	}
+
+	// the counter is followed by a single 1 bit and 99 bits of 0.
+	c := make([]byte, blockSize)
+	binary.BigEndian.PutUint32(c, counter<<4|0x8)
+	cipher.Encrypt(c, c)
+
+	m4 := make([]byte, 0, 2*blockSize)
+	m4 = append(m4, slotHeader(uid, keyID, authID)...)
+	m4 = append(m4, c...)
+	return m4, nil
+}
+
+// M5 calculates the verification message M5 = CMAC,K4(M4), where K4 = KDF(newKey, KEY_UPDATE_MAC_C).
+// newKey is the raw 128 bits key, and m4 is the raw 32 bytes M4 calculated by M4 function.
+// The output is the raw 16 bytes M5.
+func M5(newKey, m4 []byte) ([]byte, error) {
+	if len(newKey) != keySize {
+		return nil, fmt.Errorf("%w: the length of the key must be %d bytes, but %d", ErrInvalidParameter, keySize, len(newKey))
+	}
+	if len(m4) != 2*blockSize {
+		return nil, fmt.Errorf("%w: the length of M4 must be %d bytes, but %d", ErrInvalidParameter, 2*blockSize, len(m4))
+	}
+	k4, err := kdf(newKey, keyUpdateMacC)
+	if err != nil {
+		return nil, err
+	}
+	return cmac(k4, m4)
+}
+
+// slotHeader builds the first block of the key update messages: UID | ID | AuthID.
+func slotHeader(uid []byte, keyID, authID uint8) []byte {
+	h := make([]byte, blockSize)
+	copy(h, uid)
+	h[uidSize] = keyID<<4 | authID&0xf
+	return h
+}
+
+// PlainKeyLoad formats the message of CMD_LOAD_PLAIN_KEY, which loads the key into the RAM key slot without authentication.
+// Unlike the memory update protocol, the message has neither the UID | ID | AuthID header nor the encryption and the MAC:
+// it is the raw 128 bits key itself, and the slot is implied by the command.
+// Since SHE accepts a plain key only for the RAM key, slot must be SlotRAMKey.
+// The output is a copy of the key, so that the caller may clear the key after the call.
+func PlainKeyLoad(key []byte, slot uint8) ([]byte, error) {
+	if slot != SlotRAMKey {
+		return nil, fmt.Errorf("%w: a plain key can be loaded only into the RAM key slot %#x, but %#x", ErrInvalidParameter, SlotRAMKey, slot)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("%w: the length of the key must be %d bytes, but %d", ErrInvalidParameter, keySize, len(key))
+	}
+	msg := make([]byte, keySize)
+	copy(msg, key)
+	return msg, nil
+}
@@ -0,0 +1,87 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestM4M5(t *testing.T) {
+	// example of the memory update protocol described in SHE specification
+	newKey, _ := hex.DecodeString("0f0e0d0c0b0a09080706050403020100")
+	uid, _ := hex.DecodeString("000000000000000000000000000001")
+	wantM4 := "00000000000000000000000000000141b472e8d8727d70d57295e74849a27917"
+	wantM5 := "820d8d95dc11b4668878160cb2a4e23e"
+
+	m4, err := shecomp.M4(newKey, uid, shecomp.SlotKey1, shecomp.SlotMasterECUKey, 1)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if hex.EncodeToString(m4) != wantM4 {
+		t.Errorf("M4() = %x, want %s", m4, wantM4)
+	}
+
+	m5, err := shecomp.M5(newKey, m4)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if hex.EncodeToString(m5) != wantM5 {
+		t.Errorf("M5() = %x, want %s", m5, wantM5)
+	}
+}
+
+func TestM4InvalidParameter(t *testing.T) {
+	key := make([]byte, 16)
+	uid := make([]byte, 15)
+
+	tests := []struct {
+		name    string
+		key     []byte
+		uid     []byte
+		keyID   uint8
+		counter uint32
+	}{
+		{"short key", key[:15], uid, shecomp.SlotKey1, 1},
+		{"long uid", key, make([]byte, 16), shecomp.SlotKey1, 1},
+		{"key ID out of range", key, uid, 0x10, 1},
+		{"counter out of range", key, uid, shecomp.SlotKey1, 1 << 28},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := shecomp.M4(tt.key, tt.uid, tt.keyID, shecomp.SlotMasterECUKey, tt.counter)
+			if !errors.Is(err, shecomp.ErrInvalidParameter) {
+				t.Errorf("expected ErrInvalidParameter, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPlainKeyLoad(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	msg, err := shecomp.PlainKeyLoad(key, shecomp.SlotRAMKey)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	// CMD_LOAD_PLAIN_KEY takes only the 128 bits key
+	if hex.EncodeToString(msg) != hex.EncodeToString(key) {
+		t.Errorf("PlainKeyLoad() = %x, want %x", msg, key)
+	}
+	msg[0] ^= 0xff
+	if key[0] != 0x00 {
+		t.Error("PlainKeyLoad must not share the memory with the key")
+	}
+
+	if _, err := shecomp.PlainKeyLoad(key, shecomp.SlotKey1); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a non RAM key slot, got %v", err)
+	}
+	if _, err := shecomp.PlainKeyLoad(key[:15], shecomp.SlotRAMKey); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a short key, got %v", err)
+	}
+}
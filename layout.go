@@ -0,0 +1,28 @@
+package shecomp
+
+// EstimateBlocks returns the number of 128 bits blocks which Compress processes
+// for a message of sizeBytes bytes, including the padding.
+// The padding adds an extra block when the message is multiple of the block size,
+// or when the last block has more than 10 bytes.
+func EstimateBlocks(sizeBytes uint64) int {
+	tail := int(sizeBytes % blockSize)
+	return int((sizeBytes + uint64(padLen(tail))) / blockSize)
+}
+
+// MaxMessageBytesForBlocks returns the largest message length in bytes which Compress processes in exactly blocks blocks,
+// including the padding. It is the inverse of EstimateBlocks: the padding needs at least 6 bytes in the last block,
+// so the message fits in blocks blocks up to 16 * blocks - 6 bytes, and one more byte adds an extra block.
+// Since even an empty message needs one block, it returns 0 if blocks is less than 1.
+// The result is capped at the largest message SHE allows.
+func MaxMessageBytesForBlocks(blocks int) uint64 {
+	if blocks < 1 {
+		return 0
+	}
+	// the shortest padding is a byte of the single 1 bit and 7 bits of 0, followed by the 40 bits length field
+	const minPadLen = 1 + 5
+	n := uint64(blocks)*blockSize - minPadLen
+	if limit := uint64(maxBitLength / 8); n > limit {
+		return limit
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+package shecomp_test
+
+import (
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestEstimateBlocks(t *testing.T) {
+	tests := []struct {
+		size uint64
+		want int
+	}{
+		{0, 1},
+		{10, 1},
+		{11, 2},
+		{16, 2},
+		{26, 2},
+		{27, 3},
+		{32, 3},
+		{1 << 20, 1<<16 + 1},
+	}
+
+	for _, tt := range tests {
+		if got := shecomp.EstimateBlocks(tt.size); got != tt.want {
+			t.Errorf("EstimateBlocks(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestMaxMessageBytesForBlocks(t *testing.T) {
+	tests := []struct {
+		blocks int
+		want   uint64
+	}{
+		{0, 0},
+		{1, 10},
+		{2, 26},
+		{1 << 16, 1<<20 - 6},
+	}
+
+	for _, tt := range tests {
+		got := shecomp.MaxMessageBytesForBlocks(tt.blocks)
+		if got != tt.want {
+			t.Errorf("MaxMessageBytesForBlocks(%d) = %d, want %d", tt.blocks, got, tt.want)
+			continue
+		}
+		if tt.blocks < 1 {
+			continue
+		}
+		// the boundary: one more byte spills into an extra block
+		if n := shecomp.EstimateBlocks(got); n != tt.blocks {
+			t.Errorf("EstimateBlocks(%d) = %d, want %d", got, n, tt.blocks)
+		}
+		if n := shecomp.EstimateBlocks(got + 1); n != tt.blocks+1 {
+			t.Errorf("EstimateBlocks(%d) = %d, want %d", got+1, n, tt.blocks+1)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package shecomp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// SanitizeHex removes the ASCII whitespaces (space, tab, CR and LF) from the hexadecimal text s, and the 0x or 0X prefix
+// at the beginning of each word separated by the whitespaces, such as "0x6b 0xc1" or "6bc1 bee2".
+// A prefix in the middle of a word is not removed, so "6b0xc1" is rejected.
+// After removing them, it returns ErrInvalidHex if a character is not a hexadecimal digit
+// or the number of the digits is odd.
+func SanitizeHex(s []byte) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); {
+		if isSpace(s[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && !isSpace(s[j]) {
+			j++
+		}
+		word := s[i:j]
+		if len(word) >= 2 && word[0] == '0' && (word[1] == 'x' || word[1] == 'X') {
+			word = word[2:]
+		}
+		out = append(out, word...)
+		i = j
+	}
+	for i, c := range out {
+		if !isHexDigit(c) {
+			return nil, fmt.Errorf("%w at digit %d: %w", ErrInvalidHex, i, hex.InvalidByteError(c))
+		}
+	}
+	if len(out)%2 != 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHex, hex.ErrLength)
+	}
+	return out, nil
+}
+
+// CompressLenient is same as Compress, but accepts the hexadecimal text copied from datasheets,
+// which may contain the whitespaces and the 0x prefixes, removing them same as SanitizeHex.
+// Unlike SanitizeHex, it streams the input, so the input is not held in memory.
+func CompressLenient(r io.Reader) ([]byte, error) {
+	c := NewCompressor(WithWhitespaceTolerance())
+	c.skipPrefix = true
+	return c.Compress(r)
+}
@@ -0,0 +1,81 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressLenient(t *testing.T) {
+	want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"plain", "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+		{"spaced", "6b c1 be e2 2e 40 9f 96 e9 3d 7e 11 73 93 17 2a ae 2d 8a 57 1e 03 ac 9c 9e b7 6f ac 45 af 8e 51"},
+		{"newline separated", "6bc1bee22e409f96e93d7e117393172a\r\nae2d8a571e03ac9c9eb76fac45af8e51\n"},
+		{"0x prefixed", "0x6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"},
+		{"0x prefixed words", "0x6bc1bee2 0x2e409f96 0Xe93d7e11\t0x7393172a\n0xae2d8a57 0x1e03ac9c 0x9eb76fac 0x45af8e51"},
+	}
+
+	for _, tt := range tests {
+		got, err := shecomp.CompressLenient(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: CompressLenient() = %s, want %s", tt.name, got, want)
+		}
+	}
+
+	// same as SanitizeHex, even if the reads split the prefixes
+	for _, input := range []string{"0 0x0 00 0X01 0", "0x", "00x1", "0x0x01", "6b 0xc 1", "0x6b\t0", "6b\vc1", "6b\u00a0c1"} {
+		want, wantErr := shecomp.SanitizeHex([]byte(input))
+		if wantErr == nil {
+			want, _ = shecomp.Compress(bytes.NewReader(want))
+		}
+		got, err := shecomp.CompressLenient(iotest.OneByteReader(strings.NewReader(input)))
+		if (err == nil) != (wantErr == nil) {
+			t.Errorf("%q: CompressLenient() error = %v, SanitizeHex() error = %v", input, err, wantErr)
+			continue
+		}
+		if wantErr == nil && !reflect.DeepEqual(want, got) {
+			t.Errorf("%q: CompressLenient() = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestSanitizeHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{"invalid character", "6b c1 zz", hex.InvalidByteError('z')},
+		{"prefix in the middle of a word", "6b0xc1", hex.InvalidByteError('x')},
+		{"prefix only", "0x", nil},
+		{"odd length after stripping", "0x6b c", hex.ErrLength},
+		{"vertical tab is not a whitespace", "6b\vc1", hex.InvalidByteError('\v')},
+	}
+
+	for _, tt := range tests {
+		_, err := shecomp.SanitizeHex([]byte(tt.input))
+		if tt.wantErr == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, shecomp.ErrInvalidHex) || !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected ErrInvalidHex and %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package shecomp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// ErrInvalidManifest is returned when a binary manifest is malformed.
+var ErrInvalidManifest = errors.New("invalid binary manifest")
+
+// WriteManifest writes the digests into w as a binary manifest,
+// which is a sequence of records [uint16 big-endian path length][path][16 bytes digest].
+// The records are sorted by the path, so that the same digests always produce the same manifest.
+func WriteManifest(w io.Writer, digests map[string][blockSize]byte) error {
+	paths := make([]string, 0, len(digests))
+	for p := range digests {
+		if len(p) > math.MaxUint16 {
+			return fmt.Errorf("%w: the length of the path must be at most %d bytes, but %d", ErrInvalidManifest, math.MaxUint16, len(p))
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var rec []byte
+	for _, p := range paths {
+		d := digests[p]
+		rec = binary.BigEndian.AppendUint16(rec[:0], uint16(len(p)))
+		rec = append(rec, p...)
+		rec = append(rec, d[:]...)
+		if _, err := w.Write(rec); err != nil {
+			return fmt.Errorf("failed to write the manifest: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadManifest parses the binary manifest written by WriteManifest.
+// It returns ErrInvalidManifest if a record is truncated or a path appears twice.
+func ReadManifest(r io.Reader) (map[string][blockSize]byte, error) {
+	digests := make(map[string][blockSize]byte)
+	var l [2]byte
+	for {
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return digests, nil
+			}
+			return nil, manifestReadError(err)
+		}
+		p := make([]byte, binary.BigEndian.Uint16(l[:]))
+		if _, err := io.ReadFull(r, p); err != nil {
+			return nil, manifestReadError(err)
+		}
+		var d [blockSize]byte
+		if _, err := io.ReadFull(r, d[:]); err != nil {
+			return nil, manifestReadError(err)
+		}
+		if _, ok := digests[string(p)]; ok {
+			return nil, fmt.Errorf("%w: duplicated path %q", ErrInvalidManifest, p)
+		}
+		digests[string(p)] = d
+	}
+}
+
+func manifestReadError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated record", ErrInvalidManifest)
+	}
+	return fmt.Errorf("failed to read the manifest: %w", err)
+}
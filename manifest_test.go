@@ -0,0 +1,43 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	digests := map[string][16]byte{
+		"ecu/boot.bin": specDigest(),
+		"ecu/app.bin":  {0x01, 0x02},
+		"":             {},
+	}
+
+	var buf bytes.Buffer
+	if err := shecomp.WriteManifest(&buf, digests); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want := 3*(2+16) + len("ecu/boot.bin") + len("ecu/app.bin"); buf.Len() != want {
+		t.Errorf("the manifest is %d bytes, want %d", buf.Len(), want)
+	}
+	raw := bytes.Clone(buf.Bytes())
+
+	got, err := shecomp.ReadManifest(&buf)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(digests, got) {
+		t.Errorf("ReadManifest() = %v, want %v", got, digests)
+	}
+
+	for _, n := range []int{1, 2, 5, len(raw) - 1} {
+		if _, err := shecomp.ReadManifest(bytes.NewReader(raw[:n])); !errors.Is(err, shecomp.ErrInvalidManifest) {
+			t.Errorf("truncated at %d: expected ErrInvalidManifest, got %v", n, err)
+		}
+	}
+}
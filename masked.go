@@ -0,0 +1,55 @@
+package shecomp
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// WithRandom sets the source of the randomness of the Compressor, such as the mask of CompressMasked.
+// The default is crypto/rand.Reader. A fixed source makes the outputs reproducible in tests,
+// and must not be used in production.
+func WithRandom(random io.Reader) Option {
+	return func(c *Compressor) {
+		c.random = random
+	}
+}
+
+// CompressMasked compresses the input data same as Compress, and returns the raw digest split into two shares
+// with a random mask from crypto/rand: share1 is the mask and share2 is digest XOR share1.
+// The caller recovers the digest by share1[i] ^ share2[i] only where the digest is actually needed,
+// and should keep the shares apart until then.
+//
+// The digest exists as a single value only inside this function while being masked,
+// and the buffer is cleared before returning. Since Go may copy the values on its own,
+// this reduces the exposure of the digest but does not guarantee it never appears in memory.
+func CompressMasked(r io.Reader) (share1, share2 [blockSize]byte, err error) {
+	return NewCompressor().CompressMasked(r)
+}
+
+// CompressMasked is same as the package level CompressMasked function but applies the options of c,
+// taking the mask from the source set by WithRandom.
+func (c *Compressor) CompressMasked(r io.Reader) (share1, share2 [blockSize]byte, err error) {
+	d, err := c.digest(context.Background(), c.paddingReader(r))
+	if err != nil {
+		return share1, share2, err
+	}
+	defer func() {
+		for i := range d {
+			d[i] = 0
+		}
+	}()
+
+	random := c.random
+	if random == nil {
+		random = rand.Reader
+	}
+	if _, err := io.ReadFull(random, share1[:]); err != nil {
+		return share1, share2, fmt.Errorf("failed to generate the mask: %w", err)
+	}
+	for i := range share2 {
+		share2[i] = d[i] ^ share1[i]
+	}
+	return share1, share2, nil
+}
@@ -0,0 +1,65 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressMasked(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	want, err := shecomp.CompressRaw(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	share1, share2, err := shecomp.CompressMasked(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	var got [16]byte
+	for i := range got {
+		got[i] = share1[i] ^ share2[i]
+	}
+	if !bytes.Equal(got[:], want) {
+		t.Errorf("share1 XOR share2 = %x, want %x", got, want)
+	}
+
+	// the mask must change on each call
+	again, _, _ := shecomp.CompressMasked(strings.NewReader(s))
+	if again == share1 {
+		t.Error("the mask is not random")
+	}
+}
+
+func TestCompressorMaskedWithRandom(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	mask := bytes.Repeat([]byte{0x5a}, 16)
+
+	var shares [][16]byte
+	for i := 0; i < 2; i++ {
+		c := shecomp.NewCompressor(shecomp.WithRandom(bytes.NewReader(mask)))
+		share1, share2, err := c.CompressMasked(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if !bytes.Equal(share1[:], mask) {
+			t.Errorf("share1 = %x, want the fixed mask %x", share1, mask)
+		}
+		shares = append(shares, share2)
+	}
+	if shares[0] != shares[1] {
+		t.Errorf("the shares are not deterministic: %x and %x", shares[0], shares[1])
+	}
+
+	// the source is exhausted
+	c := shecomp.NewCompressor(shecomp.WithRandom(bytes.NewReader(mask[:8])))
+	if _, _, err := c.CompressMasked(strings.NewReader(s)); err == nil {
+		t.Error("expected error for the short random source")
+	}
+}
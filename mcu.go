@@ -0,0 +1,80 @@
+package shecomp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WordSwap selects how CompressForMCU reorders the bytes of the digest.
+type WordSwap int
+
+const (
+	// WordSwapNone keeps the digest as it is.
+	WordSwapNone WordSwap = iota
+	// WordSwap32 reverses the bytes within each 32 bits word, for MCUs exposing the result
+	// as four little-endian 32 bits registers.
+	WordSwap32
+	// WordSwapFull reverses the whole 128 bits digest.
+	WordSwapFull
+)
+
+// CompressForMCU compresses the input data same as Compress, and returns the raw digest reordered by swap.
+// The swap is applied only to the final digest: the compression itself is conformant to SHE,
+// so the chaining states and the digest before the reordering are same as the ones of Compress.
+func CompressForMCU(r io.Reader, swap WordSwap) ([blockSize]byte, error) {
+	if err := swap.valid(); err != nil {
+		return [blockSize]byte{}, err
+	}
+	d, err := CompressRaw(r)
+	if err != nil {
+		return [blockSize]byte{}, err
+	}
+	return swap.apply(d), nil
+}
+
+func (w WordSwap) valid() error {
+	if w < WordSwapNone || w > WordSwapFull {
+		return fmt.Errorf("%w: unknown word swap %d", ErrInvalidParameter, w)
+	}
+	return nil
+}
+
+// apply returns the digest d reordered by w.
+func (w WordSwap) apply(d []byte) [blockSize]byte {
+	var digest [blockSize]byte
+	switch w {
+	case WordSwap32:
+		for i := 0; i < blockSize; i += 4 {
+			digest[i], digest[i+1], digest[i+2], digest[i+3] = d[i+3], d[i+2], d[i+1], d[i]
+		}
+	case WordSwapFull:
+		for i := range digest {
+			digest[i] = d[blockSize-1-i]
+		}
+	default:
+		copy(digest[:], d)
+	}
+	return digest
+}
+
+// DigestWords splits the digest into four 32 bits words, each read in big-endian.
+// words[0] is the first four bytes of the digest, so for c7277a0d... it is 0xc7277a0d.
+func DigestWords(d [blockSize]byte) [4]uint32 {
+	var words [4]uint32
+	for i := range words {
+		words[i] = binary.BigEndian.Uint32(d[4*i:])
+	}
+	return words
+}
+
+// CompressUint32Words compresses the input data same as Compress, and returns the digest by DigestWords.
+// Writing words[i] to the i-th of four 32 bits registers reproduces the digest in the memory order
+// of a big-endian device; a little-endian device sees the bytes within each word reversed, same as WordSwap32.
+func CompressUint32Words(r io.Reader) ([4]uint32, error) {
+	d, err := CompressRaw(r)
+	if err != nil {
+		return [4]uint32{}, err
+	}
+	return DigestWords([blockSize]byte(d)), nil
+}
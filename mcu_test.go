@@ -0,0 +1,54 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressForMCU(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+
+	tests := []struct {
+		swap shecomp.WordSwap
+		want string
+	}{
+		{shecomp.WordSwapNone, "c7277a0dc1fb853b5f4d9cbd26be40c6"},
+		{shecomp.WordSwap32, "0d7a27c73b85fbc1bd9c4d5fc640be26"},
+		{shecomp.WordSwapFull, "c640be26bd9c4d5f3b85fbc10d7a27c7"},
+	}
+	for _, tt := range tests {
+		got, err := shecomp.CompressForMCU(strings.NewReader(s), tt.swap)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("CompressForMCU(%d) = %x, want %s", tt.swap, got, tt.want)
+		}
+	}
+
+	if _, err := shecomp.CompressForMCU(strings.NewReader(s), shecomp.WordSwap(3)); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestCompressUint32Words(t *testing.T) {
+	got, err := shecomp.CompressUint32Words(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	// c7277a0dc1fb853b5f4d9cbd26be40c6
+	want := [4]uint32{0xc7277a0d, 0xc1fb853b, 0x5f4d9cbd, 0x26be40c6}
+	if got != want {
+		t.Errorf("CompressUint32Words() = %08x, want %08x", got, want)
+	}
+
+	if _, err := shecomp.CompressUint32Words(strings.NewReader("012")); err == nil {
+		t.Error("expected error for invalid hexadecimal input")
+	}
+}
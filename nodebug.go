@@ -0,0 +1,6 @@
+//go:build !shecomp_debug
+
+package shecomp
+
+// debugAssert makes the violation of internal invariants panic.
+const debugAssert = false
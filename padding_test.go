@@ -0,0 +1,61 @@
+package shecomp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func BenchmarkPadding(b *testing.B) {
+	for _, tail := range []int{0, 5, 10, 11, 15} {
+		msg := make([]byte, tail)
+		b.Run(fmt.Sprintf("alloc/tail=%d", tail), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				padding(msg, uint64(64+tail))
+			}
+		})
+		b.Run(fmt.Sprintf("into/tail=%d", tail), func(b *testing.B) {
+			b.ReportAllocs()
+			var buf [2 * blockSize]byte
+			for i := 0; i < b.N; i++ {
+				putPadding(buf[tail:tail+padLen(tail)], uint64(64+tail))
+			}
+		})
+	}
+}
+
+func BenchmarkPutLength40(b *testing.B) {
+	var l [5]byte
+	for i := 0; i < b.N; i++ {
+		putLength40(&l, uint64(i)*8)
+	}
+}
+
+func TestPutLength40(t *testing.T) {
+	tests := []struct {
+		bytes uint64
+		want  [5]byte
+	}{
+		{0, [5]byte{}},
+		{1, [5]byte{0, 0, 0, 0, 0x08}},
+		{1<<29 - 1, [5]byte{0, 0xff, 0xff, 0xff, 0xf8}},
+		{1 << 29, [5]byte{0x01, 0, 0, 0, 0}},
+		// the largest message which the 40 bits length field can hold in bits
+		{1<<37 - 1, [5]byte{0xff, 0xff, 0xff, 0xff, 0xf8}},
+		// the bits above 40 are truncated
+		{1 << 37, [5]byte{}},
+		{1<<37 + 1, [5]byte{0, 0, 0, 0, 0x08}},
+	}
+	for _, tt := range tests {
+		var l [5]byte
+		putLength40(&l, tt.bytes*8)
+		if l != tt.want {
+			t.Errorf("putLength40(%d bits) = %x, want %x", tt.bytes*8, l, tt.want)
+		}
+
+		pad := padding(nil, tt.bytes)
+		if got := [5]byte(pad[len(pad)-5:]); got != tt.want {
+			t.Errorf("length field of padding(%d bytes) = %x, want %x", tt.bytes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package shecomp
+
+import "sync"
+
+var compressorPool = sync.Pool{
+	New: func() any { return new(Compressor) },
+}
+
+// AcquireCompressor returns a Compressor with the default options from the internal pool.
+// Its running state is empty, and it should be returned by ReleaseCompressor after use.
+func AcquireCompressor() *Compressor {
+	return compressorPool.Get().(*Compressor)
+}
+
+// ReleaseCompressor resets c and returns it to the internal pool.
+// The options of c are cleared as well, so any Compressor can be released.
+// A released Compressor must not be used again, since it may be handed to another caller.
+func ReleaseCompressor(c *Compressor) {
+	if c == nil {
+		return
+	}
+	*c = Compressor{}
+	compressorPool.Put(c)
+}
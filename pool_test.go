@@ -0,0 +1,48 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestAcquireReleaseCompressor(t *testing.T) {
+	empty, _ := shecomp.Compress(strings.NewReader(""))
+
+	seen := map[*shecomp.Compressor]bool{}
+	reused := false
+	for i := 0; i < 16; i++ {
+		c := shecomp.AcquireCompressor()
+		if seen[c] {
+			reused = true
+		}
+		seen[c] = true
+
+		// the state left by the previous user must not leak
+		if got := hex.EncodeToString(c.Sum(nil)); got != string(empty) {
+			t.Errorf("cycle %d: Sum() of an acquired compressor = %s, want %s", i, got, empty)
+		}
+		if _, err := c.Write([]byte("some message longer than a block")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		shecomp.ReleaseCompressor(c)
+	}
+	if !reused {
+		t.Error("no compressor was reused")
+	}
+
+	// options set by the previous user must not leak either
+	want, _ := shecomp.Compress(strings.NewReader("00"))
+	c := shecomp.NewCompressor(shecomp.WithBlockWhitening([16]byte{1}))
+	shecomp.ReleaseCompressor(c)
+	for i := 0; i < 4; i++ {
+		c := shecomp.AcquireCompressor()
+		got, err := c.Compress(strings.NewReader("00"))
+		if err != nil || string(got) != string(want) {
+			t.Errorf("Compress() = %s, %v, want %s", got, err, want)
+		}
+		shecomp.ReleaseCompressor(c)
+	}
+}
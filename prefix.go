@@ -0,0 +1,65 @@
+package shecomp
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// PrefixCompressor compresses many messages sharing a common prefix,
+// absorbing the prefix only once.
+// The prefix and the suffixes are raw bytes, not hexadecimal encoded.
+// The zero value has the empty prefix.
+type PrefixCompressor struct {
+	c Compressor
+}
+
+// Prefix absorbs the raw bytes read from r as the common prefix, replacing the previous one.
+// The prefix does not need to be multiple of the block size.
+func (p *PrefixCompressor) Prefix(r io.Reader) error {
+	p.c.Reset()
+	if _, err := io.Copy(&p.c, r); err != nil {
+		p.c.Reset()
+		return fmt.Errorf("could not read the prefix: %w", err)
+	}
+	return nil
+}
+
+// Complete returns the raw digest of the prefix followed by the suffix, with the padding of their combined length.
+// It does not change the state after the prefix, so it can be called for many suffixes.
+// It is safe for concurrent use as long as Prefix is not called at the same time.
+func (p *PrefixCompressor) Complete(suffix []byte) ([blockSize]byte, error) {
+	var digest [blockSize]byte
+	c := p.c
+	if _, err := c.Write(suffix); err != nil {
+		return digest, err
+	}
+	copy(digest[:], c.Sum(nil))
+	return digest, nil
+}
+
+// CompressPrefixes returns the raw digest with padding of each prefix data[:n] for n in at, keyed by n.
+// The data is absorbed only once in a single pass, and only the padding is absorbed again for each prefix.
+// Each n must be from 0 to len(data); otherwise it returns ErrInvalidParameter.
+func CompressPrefixes(data []byte, at []int) (map[int][blockSize]byte, error) {
+	points := make([]int, len(at))
+	copy(points, at)
+	sort.Ints(points)
+	if len(points) > 0 && (points[0] < 0 || points[len(points)-1] > len(data)) {
+		return nil, fmt.Errorf("%w: the prefix lengths must be from 0 to %d, but %v", ErrInvalidParameter, len(data), at)
+	}
+
+	digests := make(map[int][blockSize]byte, len(points))
+	var c Compressor
+	done := 0
+	for _, n := range points {
+		if _, err := c.Write(data[done:n]); err != nil {
+			return nil, err
+		}
+		done = n
+		var d [blockSize]byte
+		copy(d[:], c.Sum(nil))
+		digests[n] = d
+	}
+	return digests, nil
+}
@@ -0,0 +1,59 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestPrefixCompressor(t *testing.T) {
+	// the prefix ends in the middle of a block
+	prefix := "fixed header 0123456789"
+
+	var p shecomp.PrefixCompressor
+	if err := p.Prefix(strings.NewReader(prefix)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	for _, suffix := range []string{"", "a", "payload of 16 by", "a longer payload spanning a few blocks"} {
+		got, err := p.Complete([]byte(suffix))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		want, _ := shecomp.Compress(strings.NewReader(hex.EncodeToString([]byte(prefix + suffix))))
+		if hex.EncodeToString(got[:]) != string(want) {
+			t.Errorf("Complete(%q) = %x, want %s", suffix, got, want)
+		}
+	}
+}
+
+func TestCompressPrefixes(t *testing.T) {
+	data, _ := hex.DecodeString(strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 2))
+	at := []int{64, 0, 11, 16, 33, 11}
+
+	got, err := shecomp.CompressPrefixes(data, at)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if len(got) != 5 {
+		t.Errorf("got %d digests, want 5", len(got))
+	}
+	for _, n := range at {
+		want, _ := shecomp.Compress(strings.NewReader(hex.EncodeToString(data[:n])))
+		d := got[n]
+		if hex.EncodeToString(d[:]) != string(want) {
+			t.Errorf("prefix %d: digest = %x, want %s", n, d, want)
+		}
+	}
+
+	for _, bad := range [][]int{{-1}, {65}} {
+		if _, err := shecomp.CompressPrefixes(data, bad); !errors.Is(err, shecomp.ErrInvalidParameter) {
+			t.Errorf("%v: expected ErrInvalidParameter, got %v", bad, err)
+		}
+	}
+}
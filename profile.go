@@ -0,0 +1,49 @@
+package shecomp
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// DeviceProfile is a named preset of the quirks of a hardware SHE engine,
+// combining the layout of the padding and the byte order of the returned digest.
+type DeviceProfile int
+
+const (
+	// ProfileStandard conforms to SHE specification: PaddingSHE and WordSwapNone.
+	ProfileStandard DeviceProfile = iota
+	// ProfileVendorA computes the standard digest, but returns it as four little-endian 32 bits registers:
+	// PaddingSHE and WordSwap32.
+	ProfileVendorA
+	// ProfileVendorB writes the 40 bits length field of the padding in little-endian, and returns the digest as it is:
+	// PaddingLittleEndianLength and WordSwapNone. The digest differs from the standard one.
+	ProfileVendorB
+)
+
+type deviceQuirks struct {
+	scheme PaddingScheme
+	swap   WordSwap
+}
+
+var deviceProfiles = map[DeviceProfile]deviceQuirks{
+	ProfileStandard: {PaddingSHE, WordSwapNone},
+	ProfileVendorA:  {PaddingSHE, WordSwap32},
+	ProfileVendorB:  {PaddingLittleEndianLength, WordSwapNone},
+}
+
+// CompressForProfile compresses the hexadecimal encoded input data with padding, applying the quirks of the profile p,
+// and returns the raw digest. It returns ErrInvalidParameter if p is not defined.
+func CompressForProfile(r io.Reader, p DeviceProfile) ([blockSize]byte, error) {
+	q, ok := deviceProfiles[p]
+	if !ok {
+		return [blockSize]byte{}, fmt.Errorf("%w: unknown device profile %d", ErrInvalidParameter, p)
+	}
+	br := newPaddingReader(r)
+	br.scheme = q.scheme
+	d, err := NewCompressor().digest(context.Background(), br)
+	if err != nil {
+		return [blockSize]byte{}, err
+	}
+	return q.swap.apply(d), nil
+}
@@ -0,0 +1,40 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressForProfile(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+
+	tests := []struct {
+		name    string
+		profile shecomp.DeviceProfile
+		want    string
+	}{
+		{"standard", shecomp.ProfileStandard, "c7277a0dc1fb853b5f4d9cbd26be40c6"},
+		{"vendor A swaps the 32 bits words", shecomp.ProfileVendorA, "0d7a27c73b85fbc1bd9c4d5fc640be26"},
+		{"vendor B writes the length in little-endian", shecomp.ProfileVendorB, "eaa652355c6adb8f1b817683109c0872"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shecomp.CompressForProfile(strings.NewReader(s), tt.profile)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if hex.EncodeToString(got[:]) != tt.want {
+				t.Errorf("CompressForProfile() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := shecomp.CompressForProfile(strings.NewReader(s), shecomp.DeviceProfile(-1)); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
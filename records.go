@@ -0,0 +1,54 @@
+package shecomp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrShortRecord is returned by CompressRecords when the last record is shorter than the record size.
+var ErrShortRecord = errors.New("the last record is shorter than the record size")
+
+// CompressRecords reads the raw records of recordSize bytes from r until the end,
+// and returns the raw digest of each record compressed with padding, in order.
+// If the input ends in the middle of a record, it returns ErrShortRecord together with the digests of the complete records.
+// Use CompressRecordsAllowShort to compress the short last record instead.
+func CompressRecords(r io.Reader, recordSize int) (digests [][blockSize]byte, err error) {
+	return compressRecords(r, recordSize, false)
+}
+
+// CompressRecordsAllowShort is same as CompressRecords, but compresses the short last record as it is.
+func CompressRecordsAllowShort(r io.Reader, recordSize int) (digests [][blockSize]byte, err error) {
+	return compressRecords(r, recordSize, true)
+}
+
+func compressRecords(r io.Reader, recordSize int, allowShort bool) ([][blockSize]byte, error) {
+	if recordSize <= 0 {
+		return nil, fmt.Errorf("%w: the record size must be positive, but %d", ErrInvalidParameter, recordSize)
+	}
+	var digests [][blockSize]byte
+	record := make([]byte, recordSize)
+	for {
+		n, err := io.ReadFull(r, record)
+		switch {
+		case errors.Is(err, io.EOF):
+			return digests, nil
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			if !allowShort {
+				return digests, fmt.Errorf("%w: record %d has %d of %d bytes", ErrShortRecord, len(digests), n, recordSize)
+			}
+		case err != nil:
+			return digests, fmt.Errorf("could not read from reader: %w", err)
+		}
+
+		d, cerr := compressBytes(record[:n])
+		if cerr != nil {
+			return digests, cerr
+		}
+		digests = append(digests, d)
+		if err != nil {
+			// the short last record
+			return digests, nil
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package shecomp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressRecords(t *testing.T) {
+	records := []string{"record-0001", "record-0002", "record-0003"}
+	s := strings.Join(records, "")
+
+	// the records are assembled from the split reads
+	got, err := shecomp.CompressRecords(iotest.HalfReader(strings.NewReader(s)), len(records[0]))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if len(got) != len(records) {
+		t.Errorf("got %d digests, want %d", len(got), len(records))
+		return
+	}
+	for i, rec := range records {
+		want, _ := shecomp.CompressText(rec)
+		if got[i] != want {
+			t.Errorf("digest %d = %x, want %x", i, got[i], want)
+		}
+	}
+
+	short := s + "rec"
+	got, err = shecomp.CompressRecords(strings.NewReader(short), len(records[0]))
+	if !errors.Is(err, shecomp.ErrShortRecord) {
+		t.Errorf("expected ErrShortRecord, got %v", err)
+	}
+	if len(got) != len(records) {
+		t.Errorf("got %d digests of the complete records, want %d", len(got), len(records))
+	}
+
+	got, err = shecomp.CompressRecordsAllowShort(strings.NewReader(short), len(records[0]))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want, _ := shecomp.CompressText("rec"); len(got) != len(records)+1 || got[len(records)] != want {
+		t.Errorf("the short record is not compressed: %x", got)
+	}
+}
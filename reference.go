@@ -0,0 +1,75 @@
+package shecomp
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"fmt"
+)
+
+// ErrCrossCheckMismatch is returned by CrossCheck when the optimized implementation disagrees with the reference one.
+var ErrCrossCheckMismatch = errors.New("the digest disagrees with the reference implementation")
+
+// CrossCheck compresses the raw data both by the normal path and by a deliberately naive reference implementation,
+// and returns ErrCrossCheckMismatch if they disagree.
+// It is a differential self-check to catch bugs of the optimized path, and is slow for large data.
+func CrossCheck(data []byte) error {
+	var c Compressor
+	if _, err := c.Write(data); err != nil {
+		return err
+	}
+	got := c.Sum(nil)
+
+	want, err := referenceCompress(data)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: got %x, want %x", ErrCrossCheckMismatch, got, want)
+	}
+
+	// the block reader path with hexadecimal input
+	got, err = CompressRaw(bytes.NewReader(encodeHex(data)))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: got %x, want %x", ErrCrossCheckMismatch, got, want)
+	}
+	return nil
+}
+
+// referenceCompress is a straightforward implementation of SHE compression over the raw data,
+// written independently from the rest of this package: it builds the whole padded message in memory,
+// and applies the Miyaguchi-Preenel step block by block.
+func referenceCompress(data []byte) ([]byte, error) {
+	bitLen := uint64(len(data)) * 8
+	if bitLen > maxBitLength {
+		return nil, ErrLargePlainText
+	}
+
+	// message || 1 || 0...0 || 40 bits length
+	m := append([]byte{}, data...)
+	m = append(m, 0x80)
+	for (len(m)+5)%16 != 0 {
+		m = append(m, 0x00)
+	}
+	for shift := 32; shift >= 0; shift -= 8 {
+		m = append(m, byte(bitLen>>shift))
+	}
+
+	h := make([]byte, 16)
+	for i := 0; i < len(m); i += 16 {
+		x := m[i : i+16]
+		c, err := aes.NewCipher(h)
+		if err != nil {
+			return nil, err
+		}
+		e := make([]byte, 16)
+		c.Encrypt(e, x)
+		for j := range h {
+			h[j] ^= e[j] ^ x[j]
+		}
+	}
+	return h, nil
+}
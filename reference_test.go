@@ -0,0 +1,20 @@
+package shecomp_test
+
+import (
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCrossCheck(t *testing.T) {
+	// cover every residue of the block size over several blocks
+	for n := 0; n <= 80; n++ {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i*31 + n)
+		}
+		if err := shecomp.CrossCheck(data); err != nil {
+			t.Errorf("%d bytes: %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package shecomp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+)
+
+// Result is the digest of a compression with padding together with the metadata of the message.
+type Result struct {
+	Digest       [blockSize]byte
+	MessageBytes uint64 // the length of the message in bytes, excluding the padding
+	Blocks       int    // the number of blocks processed, including the padding
+}
+
+// NewResult returns the Result of the digest of a message of messageBytes bytes.
+// Blocks is derived from messageBytes same as EstimateBlocks.
+func NewResult(digest [blockSize]byte, messageBytes uint64) Result {
+	return Result{
+		Digest:       digest,
+		MessageBytes: messageBytes,
+		Blocks:       EstimateBlocks(messageBytes),
+	}
+}
+
+// Hex returns the digest encoded in hexadecimal.
+func (r Result) Hex() string {
+	return hex.EncodeToString(r.Digest[:])
+}
+
+// Raw returns the raw digest.
+func (r Result) Raw() [blockSize]byte {
+	return r.Digest
+}
+
+// Equal reports whether other is same as the raw digest, in constant time.
+func (r Result) Equal(other []byte) bool {
+	return subtle.ConstantTimeCompare(r.Digest[:], other) == 1
+}
+
+// CompressResultFull compresses the input data same as Compress, and returns the Result.
+func CompressResultFull(r io.Reader) (Result, error) {
+	br := newPaddingReader(r)
+	d, err := NewCompressor().digest(context.Background(), br)
+	if err != nil {
+		return Result{}, err
+	}
+	return NewResult([blockSize]byte(d), br.readBytes), nil
+}
+
+// Stats describes how much a compression has processed.
+type Stats struct {
+	MessageBytes uint64 // the length of the message in bytes, excluding the padding
+	PaddingBytes int    // the length of the padding in bytes
+	Blocks       int    // the number of blocks processed, including the padding
+}
+
+// CompressDetailed compresses the input data same as Compress, and also returns the Stats of the compression.
+// Unlike Result, the Stats are counted while processing the input rather than derived from the length.
+// The Stats are not filled if it returns an error.
+func CompressDetailed(r io.Reader) (digest []byte, info Stats, err error) {
+	br := newPaddingReader(r)
+	c := &Compressor{
+		observe: func(int, []byte, []byte) error {
+			info.Blocks++
+			return nil
+		},
+	}
+	digest, err = c.run(br)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	info.MessageBytes = br.readBytes
+	info.PaddingBytes = len(br.pad)
+	return digest, info, nil
+}
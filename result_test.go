@@ -0,0 +1,72 @@
+package shecomp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressResultFull(t *testing.T) {
+	res, err := shecomp.CompressResultFull(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	d := specDigest()
+	want := shecomp.Result{Digest: d, MessageBytes: 32, Blocks: 3}
+	if res != want {
+		t.Errorf("CompressResultFull() = %+v, want %+v", res, want)
+	}
+	if got := shecomp.NewResult(d, 32); got != want {
+		t.Errorf("NewResult() = %+v, want %+v", got, want)
+	}
+
+	if res.Hex() != "c7277a0dc1fb853b5f4d9cbd26be40c6" {
+		t.Errorf("Hex() = %s", res.Hex())
+	}
+	if res.Raw() != d {
+		t.Errorf("Raw() = %x, want %x", res.Raw(), d)
+	}
+	if !res.Equal(d[:]) {
+		t.Error("Equal() = false for the same digest")
+	}
+	if res.Equal(d[:15]) {
+		t.Error("Equal() = true for a truncated digest")
+	}
+	d[0] ^= 1
+	if res.Equal(d[:]) {
+		t.Error("Equal() = true for a different digest")
+	}
+}
+
+func TestCompressDetailed(t *testing.T) {
+	tests := []struct {
+		input string
+		want  shecomp.Stats
+	}{
+		{"", shecomp.Stats{MessageBytes: 0, PaddingBytes: 16, Blocks: 1}},
+		{strings.Repeat("88", 10), shecomp.Stats{MessageBytes: 10, PaddingBytes: 6, Blocks: 1}},
+		{strings.Repeat("88", 11), shecomp.Stats{MessageBytes: 11, PaddingBytes: 21, Blocks: 2}},
+		{"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", shecomp.Stats{MessageBytes: 32, PaddingBytes: 16, Blocks: 3}},
+	}
+
+	for _, tt := range tests {
+		want, _ := shecomp.Compress(strings.NewReader(tt.input))
+		got, info, err := shecomp.CompressDetailed(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("%d bytes: digest = %s, want %s", len(tt.input)/2, got, want)
+		}
+		if info != tt.want {
+			t.Errorf("%d bytes: Stats = %+v, want %+v", len(tt.input)/2, info, tt.want)
+		}
+	}
+
+	if _, _, err := shecomp.CompressDetailed(strings.NewReader("012")); err == nil {
+		t.Error("expected error for invalid hexadecimal input")
+	}
+}
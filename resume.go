@@ -0,0 +1,48 @@
+package shecomp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CompressFrom is same as Compress, but resumes the compression from the chaining state iv
+// after consumed bytes of the message, instead of starting from the zero state.
+// It lets a long message split into several segments be compressed one by one:
+// compress each segment but the last one by CompressWithoutPadding from the previous state,
+// and pass the resulting state and the total length of the segments so far to CompressFrom.
+// The padding is calculated from consumed plus the length of r, so it is same as the one of the whole message.
+//
+// iv must be 16 bytes, and consumed must be multiple of the block size since the state covers only whole blocks;
+// otherwise it returns ErrInvalidParameter. The digest is conformant to SHE only if iv and consumed are
+// actually the state and the length of the preceding part of the message; any other pair silently produces
+// a non-spec digest.
+func CompressFrom(r io.Reader, iv []byte, consumed uint64) ([]byte, error) {
+	if len(iv) != blockSize {
+		return nil, fmt.Errorf("%w: the length of the initial value must be %d bytes, but %d", ErrInvalidParameter, blockSize, len(iv))
+	}
+	if consumed%blockSize != 0 {
+		return nil, fmt.Errorf("%w: the consumed length must be multiple of %d bytes, but %d", ErrInvalidParameter, blockSize, consumed)
+	}
+	if consumed > maxBitLength/8 {
+		return nil, ErrLargePlainText
+	}
+
+	c := &Compressor{n: consumed}
+	copy(c.state[:], iv)
+	b := make([]byte, blockSize)
+	decode := newHexDecoder()
+	for {
+		n, err := decode(b, r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read from reader: %w", err)
+		}
+		if _, err := c.Write(b[:n]); err != nil {
+			return nil, err
+		}
+	}
+	return encodeHex(c.Sum(nil)), nil
+}
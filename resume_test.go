@@ -0,0 +1,56 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressFrom(t *testing.T) {
+	msg := strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 3) + "0123456789"
+	want, _ := shecomp.Compress(strings.NewReader(msg))
+
+	// split at the block boundaries, including the empty head and the empty tail
+	for _, split := range []int{0, 32, 64, 160, 192} {
+		head, tail := msg[:split], msg[split:]
+		iv := make([]byte, 16)
+		if split > 0 {
+			state, err := shecomp.CompressWithoutPadding(strings.NewReader(head))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				continue
+			}
+			iv, _ = hex.DecodeString(string(state))
+		}
+		got, err := shecomp.CompressFrom(strings.NewReader(tail), iv, uint64(split/2))
+		if err != nil {
+			t.Errorf("split %d: unexpected error: %v", split, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("split %d: CompressFrom() = %s, want %s", split, got, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		iv       []byte
+		consumed uint64
+		want     error
+	}{
+		{make([]byte, 15), 0, shecomp.ErrInvalidParameter},
+		{make([]byte, 16), 8, shecomp.ErrInvalidParameter},
+		{make([]byte, 16), 1 << 37, shecomp.ErrLargePlainText},
+		{make([]byte, 16), 1 << 61, shecomp.ErrLargePlainText}, // overflows to 0 in bit
+	} {
+		if _, err := shecomp.CompressFrom(strings.NewReader(""), tt.iv, tt.consumed); !errors.Is(err, tt.want) {
+			t.Errorf("CompressFrom(%d bytes iv, %d): expected %v, got %v", len(tt.iv), tt.consumed, tt.want, err)
+		}
+	}
+	if _, err := shecomp.CompressFrom(strings.NewReader("012"), make([]byte, 16), 0); err == nil {
+		t.Error("expected error for invalid hexadecimal input")
+	}
+}
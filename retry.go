@@ -0,0 +1,49 @@
+package shecomp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RetryPolicy configures the retry of transient read errors in CompressRetry.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of consecutive retries of a failing read.
+	MaxRetries int
+	// Backoff is the wait before the first retry. It is doubled on each consecutive retry.
+	Backoff time.Duration
+	// IsRetryable reports whether the read error is transient. If nil, no error is retried.
+	IsRetryable func(error) bool
+}
+
+// CompressRetry is same as Compress, but retries the reads from r which fail with a transient error.
+// The bytes already read are kept, so a retry resumes the block being read.
+// io.EOF and the errors which IsRetryable rejects are not retried.
+func CompressRetry(r io.Reader, policy RetryPolicy) ([]byte, error) {
+	return Compress(&retryReader{r: r, policy: policy})
+}
+
+type retryReader struct {
+	r      io.Reader
+	policy RetryPolicy
+}
+
+func (r *retryReader) Read(p []byte) (int, error) {
+	backoff := r.policy.Backoff
+	for retry := 0; ; retry++ {
+		n, err := r.r.Read(p)
+		if err == nil || errors.Is(err, io.EOF) || r.policy.IsRetryable == nil || !r.policy.IsRetryable(err) {
+			return n, err
+		}
+		if n > 0 {
+			// keep the progress. a persistent error is returned again by the next read.
+			return n, nil
+		}
+		if retry >= r.policy.MaxRetries {
+			return 0, fmt.Errorf("gave up after %d retries: %w", retry, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
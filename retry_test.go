@@ -0,0 +1,68 @@
+package shecomp_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+var errTransient = errors.New("transient")
+
+// flakyReader fails with errTransient the given number of times before each successful read.
+type flakyReader struct {
+	r     io.Reader
+	fails int
+	count int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.count < r.fails {
+		r.count++
+		return 0, errTransient
+	}
+	r.count = 0
+	if len(p) > 5 {
+		p = p[:5]
+	}
+	return r.r.Read(p)
+}
+
+func TestCompressRetry(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")
+	isTransient := func(err error) bool { return errors.Is(err, errTransient) }
+
+	policy := shecomp.RetryPolicy{
+		MaxRetries:  2,
+		Backoff:     time.Microsecond,
+		IsRetryable: isTransient,
+	}
+	got, err := shecomp.CompressRetry(&flakyReader{r: strings.NewReader(s), fails: 2}, policy)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressRetry() = %s, want %s", got, want)
+	}
+
+	// more failures than allowed
+	if _, err := shecomp.CompressRetry(&flakyReader{r: strings.NewReader(s), fails: 3}, policy); !errors.Is(err, errTransient) {
+		t.Errorf("expected errTransient, got %v", err)
+	}
+
+	// permanent errors are not retried
+	policy.IsRetryable = func(error) bool { return false }
+	r := &flakyReader{r: strings.NewReader(s), fails: 1}
+	if _, err := shecomp.CompressRetry(r, policy); !errors.Is(err, errTransient) {
+		t.Errorf("expected errTransient, got %v", err)
+	}
+	if r.count != 1 {
+		t.Errorf("permanent error was read %d times, want 1", r.count)
+	}
+}
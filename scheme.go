@@ -0,0 +1,111 @@
+package shecomp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// PaddingScheme selects the layout of the padding.
+// PaddingSHE is the only scheme conforming to SHE specification.
+// The others reproduce quirks of some devices, and produce non-standard digests.
+type PaddingScheme int
+
+const (
+	// PaddingSHE appends a single 1 bit, 0 bits and the message length in bits as 40 bits big-endian.
+	PaddingSHE PaddingScheme = iota
+	// PaddingLittleEndianLength is same as PaddingSHE, but the 40 bits length field is little-endian.
+	PaddingLittleEndianLength
+	// PaddingByteLength is same as PaddingSHE, but the length field holds the message length in bytes instead of bits.
+	PaddingByteLength
+	// PaddingExtendedLength is same as PaddingSHE, but the length field is 64 bits big-endian,
+	// which allows messages up to 1<<64 - 1 bits. Some SHE-derived implementations use it.
+	PaddingExtendedLength
+)
+
+const maxExtendedBitLength = 1<<64 - 1
+
+// ErrUnknownPaddingScheme is returned when the given PaddingScheme is not defined.
+var ErrUnknownPaddingScheme = errors.New("unknown padding scheme")
+
+func (s PaddingScheme) valid() error {
+	switch s {
+	case PaddingSHE, PaddingLittleEndianLength, PaddingByteLength, PaddingExtendedLength:
+		return nil
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownPaddingScheme, int(s))
+	}
+}
+
+// padLen returns the length of the padding in the layout of s for the message tail of tailLen bytes.
+func (s PaddingScheme) padLen(tailLen int) int {
+	if s == PaddingExtendedLength {
+		return padLenFor(tailLen, 64)
+	}
+	return padLen(tailLen)
+}
+
+// maxBytes returns the maximum length of the message in bytes which the length field of s can hold.
+func (s PaddingScheme) maxBytes() uint64 {
+	if s == PaddingExtendedLength {
+		return maxExtendedBitLength / 8
+	}
+	return maxBitLength / 8
+}
+
+// putPadding writes the padding bytes in the layout of s into dst, which length must be s.padLen of the message tail.
+func (s PaddingScheme) putPadding(pad []byte, messageByteLen uint64) {
+	if s == PaddingExtendedLength {
+		for i := range pad {
+			pad[i] = 0
+		}
+		binary.BigEndian.PutUint64(pad[len(pad)-8:], messageByteLen*8)
+		pad[0] |= 0x80
+		return
+	}
+
+	putPadding(pad, messageByteLen)
+	field := pad[len(pad)-5:]
+	switch s {
+	case PaddingLittleEndianLength:
+		for i, j := 0, len(field)-1; i < j; i, j = i+1, j-1 {
+			field[i], field[j] = field[j], field[i]
+		}
+	case PaddingByteLength:
+		for i := 0; i < 5; i++ {
+			field[len(field)-1-i] = uint8(0xff & (messageByteLen >> (8 * i)))
+		}
+	}
+	// the first bit of the padding must be 1 even if the length field is rewritten
+	pad[0] |= 0x80
+}
+
+// CompressWithScheme is same as Compress, but lays out the padding according to the given scheme.
+// It returns ErrUnknownPaddingScheme if the scheme is not defined.
+func CompressWithScheme(r io.Reader, scheme PaddingScheme) ([]byte, error) {
+	if err := scheme.valid(); err != nil {
+		return nil, err
+	}
+	br := newPaddingReader(r)
+	br.scheme = scheme
+	return NewCompressor().run(br)
+}
+
+// PaddingWithScheme is same as Padding, but lays out the padding according to the given scheme.
+// It returns ErrUnknownPaddingScheme if the scheme is not defined.
+func PaddingWithScheme(r io.Reader, scheme PaddingScheme) ([]byte, error) {
+	if err := scheme.valid(); err != nil {
+		return nil, err
+	}
+	br := newPaddingReader(r)
+	br.scheme = scheme
+	return paddingOf(br)
+}
+
+// CompressExtended is same as Compress, but uses the 64 bits length field of PaddingExtendedLength.
+// It returns ErrLargePlainText only if the length of the input is greater than 1<<64 - 1 in bit.
+// The digest differs from the one of SHE specification.
+func CompressExtended(r io.Reader) ([]byte, error) {
+	return CompressWithScheme(r, PaddingExtendedLength)
+}
@@ -0,0 +1,102 @@
+package shecomp_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestPaddingWithScheme(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+
+	tests := []struct {
+		name   string
+		scheme shecomp.PaddingScheme
+		want   []byte
+	}{
+		{
+			"SHE specification",
+			shecomp.PaddingSHE,
+			[]byte("8" + strings.Repeat("0", 28) + "100"),
+		},
+		{
+			"little-endian length",
+			shecomp.PaddingLittleEndianLength,
+			[]byte("8" + strings.Repeat("0", 21) + "0001000000"),
+		},
+		{
+			"length in bytes",
+			shecomp.PaddingByteLength,
+			[]byte("8" + strings.Repeat("0", 29) + "20"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shecomp.PaddingWithScheme(strings.NewReader(s), tt.scheme)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("PaddingWithScheme() = %s, want %s", got, tt.want)
+			}
+
+			// the digest must be same as compressing the message followed by the padding
+			want, err := shecomp.CompressWithoutPadding(strings.NewReader(s + string(got)))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			digest, err := shecomp.CompressWithScheme(strings.NewReader(s), tt.scheme)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if !reflect.DeepEqual(want, digest) {
+				t.Errorf("CompressWithScheme() = %s, want %s", digest, want)
+			}
+		})
+	}
+}
+
+func TestCompressWithUnknownScheme(t *testing.T) {
+	if _, err := shecomp.CompressWithScheme(strings.NewReader(""), shecomp.PaddingScheme(-1)); !errors.Is(err, shecomp.ErrUnknownPaddingScheme) {
+		t.Errorf("expected ErrUnknownPaddingScheme, got %v", err)
+	}
+	if _, err := shecomp.PaddingWithScheme(strings.NewReader(""), shecomp.PaddingScheme(100)); !errors.Is(err, shecomp.ErrUnknownPaddingScheme) {
+		t.Errorf("expected ErrUnknownPaddingScheme, got %v", err)
+	}
+}
+
+func TestCompressExtended(t *testing.T) {
+	s := strings.Repeat("88", 10)
+	// the 1 bit and the 64 bits length field do not fit in the first block with 10 bytes message
+	wantPad := "80" + strings.Repeat("00", 13) + "0000000000000050"
+
+	pad, err := shecomp.PaddingWithScheme(strings.NewReader(s), shecomp.PaddingExtendedLength)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if string(pad) != wantPad {
+		t.Errorf("PaddingWithScheme() = %s, want %s", pad, wantPad)
+	}
+
+	want, err := shecomp.CompressWithoutPadding(strings.NewReader(s + wantPad))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	got, err := shecomp.CompressExtended(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressExtended() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build shecomp_broken_selftest
+
+package shecomp_test
+
+import (
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+// Run by: go test -tags shecomp_broken_selftest -run SelfTest
+func TestMustPassSelfTestBroken(t *testing.T) {
+	if err := shecomp.SelfTest(); err == nil {
+		t.Error("expected error from SelfTest with the corrupted vectors")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustPassSelfTest does not panic with the corrupted vectors")
+		}
+	}()
+	shecomp.MustPassSelfTest()
+}
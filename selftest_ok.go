@@ -0,0 +1,6 @@
+//go:build !shecomp_broken_selftest
+
+package shecomp
+
+// brokenSelfTest corrupts the expected digests of the self test, to test its failure.
+const brokenSelfTest = false
@@ -0,0 +1,21 @@
+//go:build !shecomp_broken_selftest
+
+package shecomp_test
+
+import (
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestMustPassSelfTest(t *testing.T) {
+	if err := shecomp.SelfTest(); err != nil {
+		t.Error(err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("MustPassSelfTest panics on a correct build: %v", r)
+		}
+	}()
+	shecomp.MustPassSelfTest()
+}
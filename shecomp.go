@@ -4,7 +4,10 @@
 package shecomp
 
 import (
+	"context"
 	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -22,22 +25,137 @@ var ErrLargePlainText = errors.New("the length of the input text is too large. i
 // ErrNeedPadding is returned when the input text is not multiple of block size.
 var ErrNeedPadding = errors.New("the input text must be multiple of block size")
 
-type blockReader interface {
-	block(dst []byte) error
+// BlockReader is the source of the blocks processed by the compression.
+// Block fills dst, which is 16 bytes, with the next block, and returns io.EOF when no block is left.
+// Any other error stops the compression.
+type BlockReader interface {
+	Block(dst []byte) error
 }
 
+// hexDecode reads hexadecimal text from src until dst is filled, and decodes it into dst.
+// A short read of src is not regarded as the end of the input; only the end of src shortens the output.
+// It returns io.EOF only when src is exhausted before reading any byte,
+// and ErrInvalidHex wrapping the error of hex.Decode if the text is not well-formed.
 func hexDecode(dst []byte, src io.Reader) (int, error) {
-	h := make([]byte, hex.EncodedLen(len(dst)))
-	n, err := src.Read(h)
-	if err != nil {
+	return hexDecodeBuf(dst, make([]byte, hex.EncodedLen(len(dst))), src)
+}
+
+// newHexDecoder returns the decoder same as hexDecode, which reuses its buffer of the text across the calls.
+// The decoder must not be shared between the readers used concurrently.
+func newHexDecoder() decoder {
+	var buf []byte
+	return func(dst []byte, src io.Reader) (int, error) {
+		n := hex.EncodedLen(len(dst))
+		if cap(buf) < n {
+			buf = make([]byte, n)
+		}
+		return hexDecodeBuf(dst, buf[:n], src)
+	}
+}
+
+// hexDecodeBuf is same as hexDecode, but reads the text into h, which must be twice as long as dst.
+func hexDecodeBuf(dst, h []byte, src io.Reader) (int, error) {
+	n, err := io.ReadFull(src, h)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
 		return 0, err
 	}
 	h = h[:n]
-	return hex.Decode(dst, h)
+	n, err = hex.Decode(dst, h)
+	if err != nil {
+		return n, fmt.Errorf("%w: %w", ErrInvalidHex, err)
+	}
+	return n, nil
+}
+
+// rawRead is same as hexDecode, but reads raw bytes without decoding.
+func rawRead(dst []byte, src io.Reader) (int, error) {
+	n, err := io.ReadFull(src, dst)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, nil
+	}
+	return n, err
+}
+
+// spaceSkipper removes the whitespaces from the text read from r.
+// Since hexDecode fills its buffer with io.ReadFull, a byte split by a whitespace is decoded correctly.
+type spaceSkipper struct {
+	r      io.Reader
+	prefix bool // also remove the 0x or 0X prefix at the beginning of each word separated by the whitespaces
+
+	buf  []byte
+	out  []byte // the filtered text not returned yet
+	err  error
+	word bool // the last byte is in a word
+	zero bool // the 0 at the beginning of a word is held until the next byte tells if it begins a prefix
+}
+
+func (s *spaceSkipper) Read(p []byte) (int, error) {
+	// a read of whitespaces only must not be reported as an empty read
+	for len(s.out) == 0 && s.err == nil {
+		if s.buf == nil {
+			s.buf = make([]byte, 512)
+			// a held 0 may be released in addition to every byte read
+			s.out = make([]byte, 0, len(s.buf)+1)
+		}
+		n, err := s.r.Read(s.buf)
+		s.out = s.out[:0]
+		for _, b := range s.buf[:n] {
+			s.out = s.filter(s.out, b)
+		}
+		if err != nil {
+			if s.zero {
+				s.out = append(s.out, '0')
+				s.zero = false
+			}
+			s.err = err
+		}
+	}
+	if len(s.out) == 0 {
+		return 0, s.err
+	}
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+	return n, nil
+}
+
+// filter appends b to out unless b is a whitespace or a part of the prefix.
+func (s *spaceSkipper) filter(out []byte, b byte) []byte {
+	switch {
+	case isSpace(b):
+		if s.zero {
+			out = append(out, '0')
+			s.zero = false
+		}
+		s.word = false
+		return out
+	case s.zero:
+		s.zero = false
+		if b == 'x' || b == 'X' {
+			return out
+		}
+		return append(out, '0', b)
+	case s.prefix && !s.word && b == '0':
+		s.word = true
+		s.zero = true
+		return out
+	}
+	s.word = true
+	return append(out, b)
+}
+
+// decoder reads the input into dst, same as hexDecode.
+type decoder func(dst []byte, src io.Reader) (int, error)
+
+func (d decoder) read(dst []byte, src io.Reader) (int, error) {
+	if d == nil {
+		return hexDecode(dst, src)
+	}
+	return d(dst, src)
 }
 
 type noPaddingReader struct {
-	r io.Reader
+	r      io.Reader
+	decode decoder
 }
 
 type paddingReader struct {
@@ -45,11 +163,35 @@ type paddingReader struct {
 	b         []byte
 	readBytes uint64
 	pad       []byte
+	last      [2 * blockSize]byte // the message tail followed by the padding
+	rest      []byte              // blocks left in last, when the padding spans two blocks
 	eof       bool
+	tee       io.Writer // receives the decoded message bytes if not nil
+	scheme    PaddingScheme
+	decode    decoder
+	granule   uint64 // the message length must be multiple of granule if not zero
+	limit     uint64 // overrides the maximum message length of the scheme if not zero
+	noExtra   bool   // omit the padding of a non-empty message which is multiple of the block size
+	warn      *lengthWarning
 }
 
-func (r *noPaddingReader) block(dst []byte) error {
-	n, err := hexDecode(dst, r.r)
+// lengthWarning calls cb once when the message exceeds the threshold of the maximum length.
+type lengthWarning struct {
+	threshold float64
+	cb        func(usedFraction float64)
+	fired     bool
+}
+
+// maxBytes returns the maximum length of the message in bytes.
+func (r *paddingReader) maxBytes() uint64 {
+	if r.limit > 0 {
+		return r.limit
+	}
+	return r.scheme.maxBytes()
+}
+
+func (r *noPaddingReader) Block(dst []byte) error {
+	n, err := r.decode.read(dst, r.r)
 	if err != nil {
 		return err
 	}
@@ -59,63 +201,107 @@ func (r *noPaddingReader) block(dst []byte) error {
 	return nil
 }
 
+// sliceReader reads blocks from raw bytes which must be multiple of block size.
+type sliceReader struct {
+	b []byte
+}
+
+func (r *sliceReader) Block(dst []byte) error {
+	if len(r.b) == 0 {
+		return io.EOF
+	}
+	if len(r.b) < blockSize {
+		return ErrNeedPadding
+	}
+	copy(dst, r.b[:blockSize])
+	r.b = r.b[blockSize:]
+	return nil
+}
+
 func newPaddingReader(r io.Reader) *paddingReader {
 	return &paddingReader{
-		r: r,
-		b: make([]byte, blockSize),
+		r:      r,
+		b:      make([]byte, blockSize),
+		decode: newHexDecoder(),
 	}
 }
 
-func (r *paddingReader) block(dst []byte) error {
+func (r *paddingReader) Block(dst []byte) error {
 	if r.eof {
-		return io.EOF
+		if len(r.rest) == 0 {
+			return io.EOF
+		}
+		copy(dst, r.rest)
+		r.rest = r.rest[blockSize:]
+		return nil
 	}
 	// read into r.b and copy from r.b to dst
-	n, err := hexDecode(r.b, r.r)
+	n, err := r.decode.read(r.b, r.r)
 	if err != nil && !errors.Is(err, io.EOF) {
 		return err
 	}
 	r.readBytes += uint64(n)
 
-	if r.readBytes*8 > maxBitLength {
+	if r.readBytes > r.maxBytes() {
 		return ErrLargePlainText
 	}
+	if w := r.warn; w != nil && !w.fired {
+		if used := float64(r.readBytes) / float64(r.maxBytes()); used > w.threshold {
+			w.fired = true
+			w.cb(used)
+		}
+	}
+	if r.tee != nil {
+		if _, err := r.tee.Write(r.b[:n]); err != nil {
+			return err
+		}
+	}
 
 	if n == blockSize {
 		copy(dst, r.b)
 		return nil
 	}
 
+	if r.granule > 0 && r.readBytes%r.granule != 0 {
+		return fmt.Errorf("%w: the length of the message is %d bytes, not multiple of %d", ErrBadGranularity, r.readBytes, r.granule)
+	}
+
+	if r.noExtra && n == 0 && r.readBytes > 0 {
+		// the message ends at the block boundary, and the whole padding block is omitted
+		r.eof = true
+		r.pad = r.last[:0]
+		return io.EOF
+	}
+
 	// calculate padding bytes
 	r.eof = true
-	r.b = r.b[:n]
-	r.pad = padding(r.b, r.readBytes)
+	copy(r.last[:], r.b[:n])
+	end := n + r.scheme.padLen(n)
+	r.pad = r.last[n:end]
+	r.scheme.putPadding(r.pad, r.readBytes)
 
-	copy(dst, append(r.b, r.pad...))
+	// the padding spans two blocks when the tail is longer than 10 bytes
+	copy(dst, r.last[:blockSize])
+	r.rest = r.last[blockSize:end]
 	return nil
 }
 
+// padded reports whether the last block read contains the padding.
+func (r *paddingReader) padded() bool {
+	return r.eof
+}
+
+// isPadded reports whether the last block read from br contains the padding added by br.
+func isPadded(br BlockReader) bool {
+	p, ok := br.(interface{ padded() bool })
+	return ok && p.padded()
+}
+
 // compress compresses the input data using AES Miyaguchi-Preenel mode.
 // This function returns both the compressed data and the padding bytes.
 // The input data must be hexadecimal encoded.
-func compress(br blockReader) ([]byte, error) {
-	src := make([]byte, blockSize)
-	out := make([]byte, blockSize)
-
-	for {
-		if err := br.block(src); err != nil {
-			if errors.Is(err, io.EOF) {
-				return out, nil
-			}
-			return nil, fmt.Errorf("could not read from reader: %w", err)
-		}
-
-		o, err := encrypt(src, out)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt: %w", err)
-		}
-		out = o
-	}
+func compress(br BlockReader) ([]byte, error) {
+	return new(Compressor).compressContext(context.Background(), br)
 }
 
 // Compress compresses the input data using AES Miyaguchi-Preenel mode.
@@ -123,14 +309,19 @@ func compress(br blockReader) ([]byte, error) {
 // The input data must be hexadecimal encoded.
 // If the length of the input text is greater than 1<<40 - 1 in bit, it returns ErrLargePlainText.
 func Compress(r io.Reader) ([]byte, error) {
-	br := newPaddingReader(r)
-	c, err := compress(br)
-	if err != nil {
-		return nil, err
-	}
-	h := make([]byte, hex.EncodedLen(len(c)))
-	hex.Encode(h, c)
-	return h, nil
+	return NewCompressor().Compress(r)
+}
+
+// CompressRaw is same as Compress, but returns the raw 16 bytes digest instead of hexadecimal encoded one.
+func CompressRaw(r io.Reader) ([]byte, error) {
+	return NewCompressor().CompressRaw(r)
+}
+
+// CompressContext is same as Compress, but stops reading the input when ctx is done,
+// and returns the error wrapping ctx.Err().
+// The context is checked before each block, so a single blocking read of r is not interrupted.
+func CompressContext(ctx context.Context, r io.Reader) ([]byte, error) {
+	return NewCompressor().CompressContext(ctx, r)
 }
 
 // Padding calculate the padding bytes.
@@ -139,73 +330,160 @@ func Compress(r io.Reader) ([]byte, error) {
 // The input data must be hexadecimal encoded.
 // If the length of the input text is greater than 1<<40 - 1 in bit, it returns ErrLargePlainText.
 func Padding(r io.Reader) ([]byte, error) {
-	br := newPaddingReader(r)
+	return paddingOf(newPaddingReader(r))
+}
+
+// PaddingRaw is same as Padding, but returns the raw padding bytes instead of hexadecimal encoded ones.
+func PaddingRaw(r io.Reader) ([]byte, error) {
+	return rawPaddingOf(newPaddingReader(r))
+}
+
+// PaddingForLength is same as Padding, but calculates the padding from the length of the message in bytes
+// without reading the message.
+// If the length is greater than 1<<40 - 1 in bit, it returns ErrLargePlainText.
+func PaddingForLength(byteLen uint64) ([]byte, error) {
+	if byteLen > maxBitLength/8 {
+		return nil, ErrLargePlainText
+	}
+	pad := make([]byte, padLen(int(byteLen%blockSize)))
+	putPadding(pad, byteLen)
+	return encodeHex(pad), nil
+}
+
+// PaddingForHexLen is same as PaddingForLength, but takes the number of the hexadecimal digits of the message.
+// It returns ErrInvalidHex wrapping hex.ErrLength if hexDigits is odd.
+func PaddingForHexLen(hexDigits int) ([]byte, error) {
+	if hexDigits < 0 {
+		return nil, fmt.Errorf("%w: the number of digits must not be negative, but %d", ErrInvalidParameter, hexDigits)
+	}
+	if hexDigits%2 != 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHex, hex.ErrLength)
+	}
+	return PaddingForLength(uint64(hexDigits / 2))
+}
+
+func paddingOf(br *paddingReader) ([]byte, error) {
+	pad, err := rawPaddingOf(br)
+	if err != nil {
+		return nil, err
+	}
+	return encodeHex(pad), nil
+}
+
+// rawPaddingOf reads br to the end, and returns the padding appended to the message.
+func rawPaddingOf(br *paddingReader) ([]byte, error) {
 	out := make([]byte, blockSize)
 	for {
-		if err := br.block(out); err != nil {
+		if err := br.Block(out); err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
 			return nil, fmt.Errorf("failed to add padding: %w", err)
 		}
 	}
-	h := make([]byte, hex.EncodedLen(len(br.pad)))
-	hex.Encode(h, br.pad)
-	return h, nil
+	return append([]byte{}, br.pad...), nil
+}
+
+// CompressBlockReader compresses the blocks read from br using AES Miyaguchi-Preenel mode,
+// which lets a custom block source, such as a memory-mapped region, be compressed without the hexadecimal encoding.
+// No padding is added, so the blocks must already contain the padding same as CompressWithoutPadding.
+// The output is encoded in hexadecimal.
+func CompressBlockReader(br BlockReader) ([]byte, error) {
+	return NewCompressor().run(br)
 }
 
 // CompressWithoutPadding compresses the input data using AES Miyaguchi-Preenel mode.
 // CompressWithoutPadding is almost same as Compress function, but does not add padding to the end of the input data.
 // The input data must have appropriate padding according to the SHE protocol.
 func CompressWithoutPadding(r io.Reader) ([]byte, error) {
-	br := noPaddingReader{r}
-	c, err := compress(&br)
-	if err != nil {
+	return NewCompressor().CompressWithoutPadding(r)
+}
+
+// CompressWithoutPaddingContext is same as CompressWithoutPadding, but stops reading the input when ctx is done,
+// same as CompressContext.
+func CompressWithoutPaddingContext(ctx context.Context, r io.Reader) ([]byte, error) {
+	return NewCompressor().CompressWithoutPaddingContext(ctx, r)
+}
+
+func encodeHex(b []byte) []byte {
+	h := make([]byte, hex.EncodedLen(len(b)))
+	hex.Encode(h, b)
+	return h
+}
+
+func encrypt(src, previous []byte) ([]byte, error) {
+	return encryptWith(aes.NewCipher, src, previous)
+}
+
+// encryptWith is same as encrypt, but initializes the cipher keyed by the previous state with newCipher.
+func encryptWith(newCipher func(key []byte) (cipher.Block, error), src, previous []byte) ([]byte, error) {
+	out := make([]byte, blockSize)
+	if err := encryptInto(newCipher, out, src, previous); err != nil {
 		return nil, err
 	}
-	h := make([]byte, hex.EncodedLen(len(c)))
-	hex.Encode(h, c)
-	return h, nil
+	return out, nil
 }
 
-func encrypt(src, previous []byte) ([]byte, error) {
-	if len(src) != blockSize || len(previous) != blockSize {
-		return nil, fmt.Errorf("failed to encrypt. the length of each input must be same as blockSize=%d, but len(src) = %d, len(previous) = %d", blockSize, len(src), len(previous))
+// encryptInto is same as encryptWith, but writes the next state into dst without allocating it.
+// dst must not overlap src nor previous.
+func encryptInto(newCipher func(key []byte) (cipher.Block, error), dst, src, previous []byte) error {
+	if err := invariant(len(src) == blockSize && len(previous) == blockSize && len(dst) == blockSize, "failed to encrypt. the length of each input must be same as blockSize=%d, but len(src) = %d, len(previous) = %d, len(dst) = %d", blockSize, len(src), len(previous), len(dst)); err != nil {
+		return err
 	}
-	cipher, err := aes.NewCipher(previous)
+	block, err := newCipher(previous)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize aes cipher: %w", err)
+		return fmt.Errorf("failed to initialize aes cipher: %w", err)
 	}
-	encrypted := make([]byte, blockSize)
-	cipher.Encrypt(encrypted, src)
-	encrypted, _ = xor(encrypted, src)
-	out, _ := xor(previous, encrypted)
-	return out, nil
+	block.Encrypt(dst, src)
+	if err := xor(dst, dst, src); err != nil {
+		return err
+	}
+	return xor(dst, dst, previous)
 }
 
-func xor(a, b []byte) ([]byte, error) {
-	if len(a) != len(b) {
-		return nil, fmt.Errorf("failed to xor: len(a) = %d, len(b) = %d", len(a), len(b))
+// xor writes a xor b into dst, which may be same as a or b.
+func xor(dst, a, b []byte) error {
+	if err := invariant(len(a) == len(b) && len(dst) == len(a), "failed to xor: len(dst) = %d, len(a) = %d, len(b) = %d", len(dst), len(a), len(b)); err != nil {
+		return err
 	}
-	r := make([]byte, len(a))
-	for i := 0; i < len(a); i++ {
-		r[i] = a[i] ^ b[i]
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
 	}
-	return r, nil
+	return nil
 }
 
 func padding(b []byte, messageByteLen uint64) []byte {
-	padMinBitLen := 8*len(b) + 1 + 40
-	padByteLen := (padMinBitLen/128+1)*128/8 - len(b)
-	pad := make([]byte, padByteLen)
+	pad := make([]byte, padLen(len(b)))
+	putPadding(pad, messageByteLen)
+	return pad
+}
 
-	// the last 40 bits of the padding shows the length of the message in bits
-	for i := 0; i < 5; i++ {
-		pos := len(pad) - 1 - i
-		d := uint8(0xff & ((messageByteLen * 8) >> (8 * i)))
-		pad[pos] = d
+// padLen returns the length of the padding for the message tail of tailLen bytes.
+func padLen(tailLen int) int {
+	return padLenFor(tailLen, 40)
+}
+
+// padLenFor is same as padLen, but for the length field of fieldBits bits.
+func padLenFor(tailLen, fieldBits int) int {
+	padMinBitLen := 8*tailLen + 1 + fieldBits
+	return (padMinBitLen/128+1)*128/8 - tailLen
+}
+
+// putPadding writes the padding into dst without allocation.
+// The length of dst must be padLen of the message tail.
+func putPadding(dst []byte, messageByteLen uint64) {
+	for i := range dst {
+		dst[i] = 0
 	}
+	// the last 40 bits of the padding shows the length of the message in bits
+	putLength40((*[5]byte)(dst[len(dst)-5:]), messageByteLen*8)
 	// the first bit of the padding must be 1
-	pad[0] = 0x80 | pad[0]
-	return pad
+	dst[0] |= 0x80
+}
+
+// putLength40 writes the lower 40 bits of bits into l in big-endian.
+// The upper 24 bits are truncated.
+func putLength40(l *[5]byte, bits uint64) {
+	l[0] = byte(bits >> 32)
+	binary.BigEndian.PutUint32(l[1:], uint32(bits))
 }
@@ -1,9 +1,13 @@
 package shecomp_test
 
 import (
+	"encoding/hex"
+	"errors"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/tenkoh/go-shecomp"
 )
@@ -94,6 +98,38 @@ func TestCompress(t *testing.T) {
 	}
 }
 
+func TestCompressPaddingSpansTwoBlocks(t *testing.T) {
+	// a tail of 11 to 15 bytes leaves no room for the 1 bit and the 40 bits length in its block,
+	// so the padding spans two blocks, and Compress must absorb both of them.
+	for head := 0; head <= 2; head++ {
+		for tail := 11; tail <= 15; tail++ {
+			n := head*16 + tail
+			s := strings.Repeat("a5", n)
+			p, err := shecomp.Padding(strings.NewReader(s))
+			if err != nil {
+				t.Errorf("%d bytes: unexpected error: %v", n, err)
+				continue
+			}
+			if len(p)/2+tail != 2*16 {
+				t.Errorf("%d bytes: the padding is %d bytes, want %d", n, len(p)/2, 2*16-tail)
+			}
+			want, err := shecomp.CompressWithoutPadding(strings.NewReader(s + string(p)))
+			if err != nil {
+				t.Errorf("%d bytes: unexpected error: %v", n, err)
+				continue
+			}
+			got, err := shecomp.Compress(strings.NewReader(s))
+			if err != nil {
+				t.Errorf("%d bytes: unexpected error: %v", n, err)
+				continue
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("%d bytes: Compress() = %s, want %s", n, got, want)
+			}
+		}
+	}
+}
+
 func TestCompressWithoutPadding(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -130,3 +166,249 @@ func TestCompressWithoutPadding(t *testing.T) {
 		})
 	}
 }
+
+// chunkReader returns the text in chunks of the given sizes in rotation.
+type chunkReader struct {
+	s     string
+	sizes []int
+	i     int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.s) == 0 {
+		return 0, io.EOF
+	}
+	n := r.sizes[r.i%len(r.sizes)]
+	r.i++
+	if n > len(r.s) {
+		n = len(r.s)
+	}
+	if n > len(p) {
+		n = len(p)
+	}
+	copy(p, r.s[:n])
+	r.s = r.s[n:]
+	return n, nil
+}
+
+func TestCompressMixedCaseSplitReads(t *testing.T) {
+	// the case varies per character, and the reads are split between the two nibbles of a byte.
+	s := "6Bc1BeE22e409F96e93D7e117393172aAE2d8a571E03aC9c9Eb76FaC45aF8e51"
+	want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")
+
+	for _, sizes := range [][]int{{1}, {3}, {5, 7}, {31, 1}, {33}} {
+		got, err := shecomp.Compress(&chunkReader{s: s, sizes: sizes})
+		if err != nil {
+			t.Errorf("chunks %v: unexpected error: %v", sizes, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("chunks %v: Compress() = %s, want %s", sizes, got, want)
+		}
+	}
+}
+
+func TestCompressStrict(t *testing.T) {
+	for n := 0; n <= 33; n++ {
+		s := strings.Repeat("5a", n)
+		want, err := shecomp.Compress(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		got, err := shecomp.CompressStrict(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("%d bytes: unexpected error: %v", n, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%d bytes: CompressStrict() = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestCompressColumnWrapped(t *testing.T) {
+	msg := strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 3)
+	want, _ := shecomp.Compress(strings.NewReader(msg))
+	c := shecomp.NewCompressor(shecomp.WithWhitespaceTolerance())
+
+	// odd widths split a byte across the lines
+	for _, width := range []int{64, 32, 7, 1} {
+		var b strings.Builder
+		for i := 0; i < len(msg); i += width {
+			end := i + width
+			if end > len(msg) {
+				end = len(msg)
+			}
+			b.WriteString(msg[i:end])
+			b.WriteString("\r\n")
+		}
+		wrapped := b.String()
+
+		for _, sizes := range [][]int{{len(wrapped)}, {1}, {33, 5}} {
+			got, err := c.Compress(&chunkReader{s: wrapped, sizes: sizes})
+			if err != nil {
+				t.Errorf("width %d, chunks %v: unexpected error: %v", width, sizes, err)
+				continue
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("width %d, chunks %v: Compress() = %s, want %s", width, sizes, got, want)
+			}
+		}
+	}
+
+	if _, err := shecomp.Compress(strings.NewReader(msg[:64] + "\n")); err == nil {
+		t.Error("expected error for a whitespace without WithWhitespaceTolerance")
+	}
+}
+
+func TestPaddingForHexLen(t *testing.T) {
+	for _, digits := range []int{0, 2, 20, 22, 32, 64, 66} {
+		got, err := shecomp.PaddingForHexLen(digits)
+		if err != nil {
+			t.Errorf("%d digits: unexpected error: %v", digits, err)
+			continue
+		}
+		want, _ := shecomp.Padding(strings.NewReader(strings.Repeat("a", digits)))
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("PaddingForHexLen(%d) = %s, want %s", digits, got, want)
+		}
+	}
+
+	if _, err := shecomp.PaddingForHexLen(3); !errors.Is(err, shecomp.ErrInvalidHex) {
+		t.Errorf("expected ErrInvalidHex for odd digits, got %v", err)
+	}
+	if _, err := shecomp.PaddingForHexLen(-2); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for negative digits, got %v", err)
+	}
+	if _, err := shecomp.PaddingForLength(1 << 37); !errors.Is(err, shecomp.ErrLargePlainText) {
+		t.Errorf("expected ErrLargePlainText, got %v", err)
+	}
+}
+
+func TestOneByteReader(t *testing.T) {
+	// a short read in the middle of a block must not be regarded as the end of the input.
+	for n := 0; n <= 3*16; n++ {
+		s := strings.Repeat("c3", n)
+		for _, f := range []struct {
+			name string
+			fn   func(io.Reader) ([]byte, error)
+		}{
+			{"Compress", shecomp.Compress},
+			{"Padding", shecomp.Padding},
+		} {
+			want, err := f.fn(strings.NewReader(s))
+			if err != nil {
+				t.Errorf("%d bytes: unexpected error: %v", n, err)
+				continue
+			}
+			got, err := f.fn(iotest.OneByteReader(strings.NewReader(s)))
+			if err != nil {
+				t.Errorf("%d bytes: %s() unexpected error: %v", n, f.name, err)
+				continue
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("%d bytes: %s() = %s, want %s", n, f.name, got, want)
+			}
+		}
+	}
+
+	s := "000102030405060708090a0b0c0d0e0f010153484500800000000000000000b0"
+	got, err := shecomp.CompressWithoutPadding(iotest.OneByteReader(strings.NewReader(s)))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want := []byte("118a46447a770d87828a69c222e2d17e"); !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressWithoutPadding() = %s, want %s", got, want)
+	}
+}
+
+func TestRawOutput(t *testing.T) {
+	for _, s := range []string{"", strings.Repeat("88", 26), strings.Repeat("88", 32), "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"} {
+		want, _ := shecomp.Compress(strings.NewReader(s))
+		got, err := shecomp.CompressRaw(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if hex.EncodeToString(got) != string(want) {
+			t.Errorf("CompressRaw() = %x, want %s", got, want)
+		}
+
+		want, _ = shecomp.Padding(strings.NewReader(s))
+		got, err = shecomp.PaddingRaw(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if hex.EncodeToString(got) != string(want) {
+			t.Errorf("PaddingRaw() = %x, want %s", got, want)
+		}
+	}
+
+	if _, err := shecomp.PaddingRaw(strings.NewReader("012")); err == nil {
+		t.Error("expected error for invalid hexadecimal input")
+	}
+}
+
+// compressArena reads the whole input and compresses it by CompressArena.
+func compressArena(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var dst [32]byte
+	var scratch shecomp.Scratch
+	if err := shecomp.CompressArena(&dst, &scratch, data); err != nil {
+		return nil, err
+	}
+	return dst[:], nil
+}
+
+func TestInvalidHex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		cause error
+	}{
+		{"odd length", "012", hex.ErrLength},
+		{"odd length in a later block", strings.Repeat("00", 16) + "0", hex.ErrLength},
+		{"invalid character", "0g", hex.InvalidByteError('g')},
+		{"valid even length", "0123", nil},
+	}
+
+	for _, tt := range tests {
+		for _, f := range []struct {
+			name string
+			fn   func(io.Reader) ([]byte, error)
+		}{
+			{"Compress", shecomp.Compress},
+			{"Padding", shecomp.Padding},
+			{"CompressArena", compressArena},
+		} {
+			_, err := f.fn(strings.NewReader(tt.input))
+			if tt.cause == nil {
+				if err != nil {
+					t.Errorf("%s: %s() unexpected error: %v", tt.name, f.name, err)
+				}
+				continue
+			}
+			if !errors.Is(err, shecomp.ErrInvalidHex) || !errors.Is(err, tt.cause) {
+				t.Errorf("%s: %s() expected ErrInvalidHex and %v, got %v", tt.name, f.name, tt.cause, err)
+			}
+		}
+	}
+}
+
+func BenchmarkCompress(b *testing.B) {
+	// 4 MiB of message
+	s := strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 1<<17)
+	b.SetBytes(int64(len(s) / 2))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := shecomp.Compress(strings.NewReader(s)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package shecomptest provides utilities for testing the message schemas built on shecomp.
+package shecomptest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+// AssertNoCollision compresses all inputs with padding, and reports an error to t
+// if any two distinct inputs share a digest.
+// The inputs are raw bytes, not hexadecimal encoded. Duplicated inputs are not regarded as a collision.
+func AssertNoCollision(t testing.TB, inputs [][]byte) {
+	t.Helper()
+	assertNoCollision(t, inputs, sum)
+}
+
+func sum(b []byte) ([]byte, error) {
+	var c shecomp.Compressor
+	if _, err := c.Write(b); err != nil {
+		return nil, err
+	}
+	return c.Sum(nil), nil
+}
+
+func assertNoCollision(t testing.TB, inputs [][]byte, sum func([]byte) ([]byte, error)) {
+	t.Helper()
+	// the digest in hexadecimal -> the index of the first input having the digest
+	seen := make(map[string]int, len(inputs))
+	for i, in := range inputs {
+		d, err := sum(in)
+		if err != nil {
+			t.Errorf("failed to compress input %d: %v", i, err)
+			continue
+		}
+		key := hex.EncodeToString(d)
+		j, ok := seen[key]
+		if !ok {
+			seen[key] = i
+			continue
+		}
+		if !bytes.Equal(inputs[j], in) {
+			t.Errorf("collision: input %d (%x) and input %d (%x) share the digest %s", j, inputs[j], i, in, key)
+		}
+	}
+}
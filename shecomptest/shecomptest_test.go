@@ -0,0 +1,34 @@
+package shecomptest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// recorder records the errors instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertNoCollision(t *testing.T) {
+	inputs := [][]byte{{}, {0x00}, {0x00, 0x00}, []byte("ECU-1"), []byte("ECU-2"), []byte("ECU-1")}
+	AssertNoCollision(t, inputs)
+}
+
+func TestAssertNoCollisionDetects(t *testing.T) {
+	// a digest function which always collides
+	constant := func([]byte) ([]byte, error) { return make([]byte, 16), nil }
+
+	r := &recorder{}
+	assertNoCollision(r, [][]byte{{0x01}, {0x01}, {0x02}}, constant)
+	if len(r.errors) != 1 {
+		t.Errorf("got %d errors %v, want 1", len(r.errors), r.errors)
+	}
+}
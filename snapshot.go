@@ -0,0 +1,39 @@
+package shecomp
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// CompressSnapshots compresses the input data same as Compress, and also returns the chaining states
+// captured every everyBytes bytes of the message.
+// A snapshot is taken after the block in which the number of the processed message bytes reaches a multiple of everyBytes,
+// at most once per block, so an interval shorter than the block size behaves same as the block size.
+// The snapshots are the raw chaining states without the padding, not the digests of the message prefixes;
+// the blocks containing the padding are not captured.
+// Both final and snapshots are encoded in hexadecimal.
+func CompressSnapshots(r io.Reader, everyBytes int) (final []byte, snapshots [][]byte, err error) {
+	if everyBytes <= 0 {
+		return nil, nil, fmt.Errorf("%w: the interval must be positive, but %d", ErrInvalidParameter, everyBytes)
+	}
+	every := uint64(everyBytes)
+
+	c := &Compressor{}
+	br := c.paddingReader(r)
+	c.observe = func(index int, block, state []byte) error {
+		if br.padded() {
+			return nil
+		}
+		processed := uint64(index+1) * blockSize
+		if processed/every > (processed-blockSize)/every {
+			snapshots = append(snapshots, encodeHex(state))
+		}
+		return nil
+	}
+	final, err = c.runContext(context.Background(), br)
+	if err != nil {
+		return nil, nil, err
+	}
+	return final, snapshots, nil
+}
@@ -0,0 +1,55 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressSnapshots(t *testing.T) {
+	// 5 blocks and 8 bytes of the message
+	s := strings.Repeat("6bc1bee22e409f96e93d7e117393172a", 5) + "ae2d8a571e03ac9c"
+	want, _ := shecomp.Compress(strings.NewReader(s))
+
+	tests := []struct {
+		every int
+		n     int
+	}{
+		{16, 5},
+		{32, 2},
+		{24, 3}, // at 32, 48 and 80 bytes
+		{1, 5},
+		{100, 0},
+	}
+	for _, tt := range tests {
+		final, snapshots, err := shecomp.CompressSnapshots(strings.NewReader(s), tt.every)
+		if err != nil {
+			t.Errorf("every %d: unexpected error: %v", tt.every, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, final) {
+			t.Errorf("every %d: final = %s, want %s", tt.every, final, want)
+		}
+		if len(snapshots) != tt.n {
+			t.Errorf("every %d: got %d snapshots, want %d", tt.every, len(snapshots), tt.n)
+		}
+	}
+
+	// the snapshot is the raw chaining state without the padding
+	_, snapshots, _ := shecomp.CompressSnapshots(strings.NewReader(s), 32)
+	state, _ := shecomp.CompressWithoutPadding(strings.NewReader(s[:64]))
+	if string(snapshots[0]) != string(state) {
+		t.Errorf("snapshot = %s, want %s", snapshots[0], state)
+	}
+	if _, err := hex.DecodeString(string(snapshots[1])); err != nil {
+		t.Errorf("snapshot is not hexadecimal: %v", err)
+	}
+
+	if _, _, err := shecomp.CompressSnapshots(strings.NewReader(s), 0); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package shecomp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// SpillSource is an io.ReadSeeker holding the whole input, in memory up to a threshold and in a temporary file beyond it.
+// Close removes the temporary file.
+type SpillSource struct {
+	rs   io.ReadSeeker
+	file *os.File
+}
+
+// BufferedSource reads all of r, and returns a SpillSource replaying it.
+// The input is held in memory if it is not longer than memLimit bytes,
+// otherwise it spills to a temporary file, so that a large input does not exhaust the memory.
+// It is useful when the whole input must be validated (e.g. a trailing CRC) before compressing it.
+func BufferedSource(r io.Reader, memLimit int) (*SpillSource, error) {
+	var mem bytes.Buffer
+	limit := int64(memLimit)
+	if limit == math.MaxInt64 {
+		// so that limit+1 does not overflow
+		limit--
+	}
+	// read one more byte than the limit to know whether the input exceeds it
+	n, err := io.CopyN(&mem, r, limit+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read the input: %w", err)
+	}
+	if n <= limit {
+		return &SpillSource{rs: bytes.NewReader(mem.Bytes())}, nil
+	}
+
+	f, err := os.CreateTemp("", "shecomp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary file: %w", err)
+	}
+	s := &SpillSource{rs: f, file: f}
+	if _, err := io.Copy(f, io.MultiReader(&mem, r)); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to spill the input to %s: %w", f.Name(), err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to rewind %s: %w", f.Name(), err)
+	}
+	return s, nil
+}
+
+// Read implements io.Reader.
+func (s *SpillSource) Read(p []byte) (int, error) {
+	return s.rs.Read(p)
+}
+
+// Seek implements io.Seeker.
+func (s *SpillSource) Seek(offset int64, whence int) (int64, error) {
+	return s.rs.Seek(offset, whence)
+}
+
+// Spilled reports whether the input is held in a temporary file.
+func (s *SpillSource) Spilled() bool {
+	return s.file != nil
+}
+
+// Close removes the temporary file if the input spilled. It does nothing for the input in memory.
+func (s *SpillSource) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	name := s.file.Name()
+	err := s.file.Close()
+	if rerr := os.Remove(name); err == nil {
+		err = rerr
+	}
+	s.file = nil
+	return err
+}
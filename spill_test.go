@@ -0,0 +1,59 @@
+package shecomp_test
+
+import (
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestBufferedSource(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6")
+
+	tests := []struct {
+		name        string
+		memLimit    int
+		wantSpilled bool
+	}{
+		{"in memory", len(s), false},
+		{"spill to a file", 8, true},
+		{"unlimited", math.MaxInt, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := shecomp.BufferedSource(strings.NewReader(s), tt.memLimit)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			defer src.Close()
+			if src.Spilled() != tt.wantSpilled {
+				t.Errorf("Spilled() = %v, want %v", src.Spilled(), tt.wantSpilled)
+			}
+
+			// read twice to confirm the source can be replayed
+			for i := 0; i < 2; i++ {
+				if _, err := src.Seek(0, io.SeekStart); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				got, err := shecomp.Compress(src)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("Compress() = %s, want %s", got, want)
+				}
+			}
+			if err := src.Close(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package shecomp
+
+import "fmt"
+
+// FinalizeBlock applies a single Miyaguchi-Preenel step to the chaining state and the block,
+// and returns the next state: E(state, block) xor block xor state.
+// The initial state of the SHE compression is 16 bytes of zero.
+// Together with ApplyPadding, it lets callers drive their own block loop.
+func FinalizeBlock(state, lastBlock [blockSize]byte) [blockSize]byte {
+	out, err := encrypt(lastBlock[:], state[:])
+	if err != nil {
+		// unreachable: the lengths are fixed by the array types
+		panic(err)
+	}
+	var next [blockSize]byte
+	copy(next[:], out)
+	return next
+}
+
+// ApplyPadding absorbs the message tail followed by the SHE padding of a message of msgLen bytes into the state,
+// and returns the digest.
+// The state must be the result of absorbing the whole blocks of the message by FinalizeBlock,
+// and tail must be the rest of the message, which is msgLen mod 16 bytes.
+// The padding spans one block, or two blocks if the tail is longer than 10 bytes.
+// It returns ErrInvalidParameter if the length of tail does not match msgLen,
+// and ErrLargePlainText if msgLen is greater than 1<<40 - 1 in bit.
+func ApplyPadding(state [blockSize]byte, tail []byte, msgLen uint64) ([blockSize]byte, error) {
+	if msgLen > maxBitLength/8 {
+		return state, ErrLargePlainText
+	}
+	if uint64(len(tail)) != msgLen%blockSize {
+		return state, fmt.Errorf("%w: the tail must be %d bytes for the message of %d bytes, but %d", ErrInvalidParameter, msgLen%blockSize, msgLen, len(tail))
+	}
+	var last [2 * blockSize]byte
+	copy(last[:], tail)
+	end := len(tail) + padLen(len(tail))
+	putPadding(last[len(tail):end], msgLen)
+	for i := 0; i < end; i += blockSize {
+		state = FinalizeBlock(state, [blockSize]byte(last[i:i+blockSize]))
+	}
+	return state, nil
+}
+
+// CompressBlocks runs the compression over the pre-split blocks and returns the raw digest.
+// If pad is true, the message is the concatenation of the blocks, which is 16 * len(blocks) bytes,
+// and its padding is absorbed as an extra block same as Compress.
+// Otherwise the blocks must already contain the padding, same as CompressWithoutPadding.
+// It returns ErrLargePlainText if the message is too large.
+func CompressBlocks(blocks [][blockSize]byte, pad bool) ([blockSize]byte, error) {
+	var state [blockSize]byte
+	if uint64(len(blocks))*blockSize*8 > maxBitLength {
+		return state, ErrLargePlainText
+	}
+	for _, b := range blocks {
+		state = FinalizeBlock(state, b)
+	}
+	if pad {
+		return ApplyPadding(state, nil, uint64(len(blocks))*blockSize)
+	}
+	return state, nil
+}
@@ -0,0 +1,117 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestFinalizeBlockLoop(t *testing.T) {
+	message, _ := hex.DecodeString(strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 2))
+
+	// cover the tails which need one and two blocks of the padding
+	for n := 0; n <= len(message); n++ {
+		var state [16]byte
+		full := n - n%16
+		for i := 0; i < full; i += 16 {
+			state = shecomp.FinalizeBlock(state, [16]byte(message[i:i+16]))
+		}
+		digest, err := shecomp.ApplyPadding(state, message[full:n], uint64(n))
+		if err != nil {
+			t.Errorf("%d bytes: unexpected error: %v", n, err)
+			continue
+		}
+		want, _ := shecomp.Compress(strings.NewReader(hex.EncodeToString(message[:n])))
+		if got := hex.EncodeToString(digest[:]); got != string(want) {
+			t.Errorf("%d bytes: hand-driven loop = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestApplyPaddingInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		tail   []byte
+		msgLen uint64
+		want   error
+	}{
+		{nil, 15, shecomp.ErrInvalidParameter},
+		{make([]byte, 3), 16, shecomp.ErrInvalidParameter},
+		{nil, 1 << 37, shecomp.ErrLargePlainText},
+	} {
+		if _, err := shecomp.ApplyPadding([16]byte{}, tt.tail, tt.msgLen); !errors.Is(err, tt.want) {
+			t.Errorf("ApplyPadding(%d bytes tail, %d): expected %v, got %v", len(tt.tail), tt.msgLen, tt.want, err)
+		}
+	}
+}
+
+func TestCompressBlocks(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	raw, _ := hex.DecodeString(s)
+	blocks := make([][16]byte, 2)
+	copy(blocks[0][:], raw[:16])
+	copy(blocks[1][:], raw[16:])
+
+	got, err := shecomp.CompressBlocks(blocks, true)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want, _ := shecomp.Compress(strings.NewReader(s))
+	if hex.EncodeToString(got[:]) != string(want) {
+		t.Errorf("CompressBlocks(pad = true) = %x, want %s", got, want)
+	}
+
+	got, err = shecomp.CompressBlocks(blocks, false)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want, _ = shecomp.CompressWithoutPadding(strings.NewReader(s))
+	if hex.EncodeToString(got[:]) != string(want) {
+		t.Errorf("CompressBlocks(pad = false) = %x, want %s", got, want)
+	}
+
+	got, _ = shecomp.CompressBlocks(nil, true)
+	want, _ = shecomp.Compress(strings.NewReader(""))
+	if hex.EncodeToString(got[:]) != string(want) {
+		t.Errorf("CompressBlocks(nil, true) = %x, want %s", got, want)
+	}
+}
+
+// memoryBlocks is a BlockReader over the raw bytes in memory.
+type memoryBlocks struct {
+	b []byte
+}
+
+func (m *memoryBlocks) Block(dst []byte) error {
+	if len(m.b) == 0 {
+		return io.EOF
+	}
+	if len(m.b) < 16 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(dst, m.b[:16])
+	m.b = m.b[16:]
+	return nil
+}
+
+func TestCompressBlockReader(t *testing.T) {
+	raw, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f010153484500800000000000000000b0")
+	got, err := shecomp.CompressBlockReader(&memoryBlocks{raw})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want := []byte("118a46447a770d87828a69c222e2d17e"); !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressBlockReader() = %s, want %s", got, want)
+	}
+
+	if _, err := shecomp.CompressBlockReader(&memoryBlocks{raw[:24]}); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the error of the block reader, got %v", err)
+	}
+}
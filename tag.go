@@ -0,0 +1,60 @@
+package shecomp
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+)
+
+// CompressAppendTag returns message || CMAC(key, message), a self-contained authenticated blob.
+// The key must be a raw 128 bits key. The message and the output are raw bytes.
+func CompressAppendTag(message, key []byte) ([]byte, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("%w: the length of the key must be %d bytes, but %d", ErrInvalidParameter, keySize, len(key))
+	}
+	tag, err := cmac(key, message)
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, 0, len(message)+len(tag))
+	blob = append(blob, message...)
+	return append(blob, tag...), nil
+}
+
+// VerifyAppendedTag splits the last 16 bytes of the blob created by CompressAppendTag as the tag,
+// and reports whether the tag matches the CMAC of the rest in constant time.
+// The returned message is a sub-slice of blob, and is returned even if the tag does not match.
+func VerifyAppendedTag(blob, key []byte) (message []byte, ok bool, err error) {
+	if len(key) != keySize {
+		return nil, false, fmt.Errorf("%w: the length of the key must be %d bytes, but %d", ErrInvalidParameter, keySize, len(key))
+	}
+	if len(blob) < blockSize {
+		return nil, false, fmt.Errorf("%w: the blob must be at least %d bytes, but %d", ErrInvalidParameter, blockSize, len(blob))
+	}
+	message, tag := blob[:len(blob)-blockSize], blob[len(blob)-blockSize:]
+	want, err := cmac(key, message)
+	if err != nil {
+		return nil, false, err
+	}
+	return message, subtle.ConstantTimeCompare(want, tag) == 1, nil
+}
+
+// CompressSelfVerifying reads the raw blob of a message followed by its own 16 bytes digest from r,
+// and reports whether the digest matches the compression of the message with padding in constant time.
+// The message is returned even if the digest does not match.
+// It returns ErrInvalidParameter if the blob is shorter than 16 bytes.
+func CompressSelfVerifying(r io.Reader) (message []byte, ok bool, err error) {
+	blob, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read from reader: %w", err)
+	}
+	if len(blob) < blockSize {
+		return nil, false, fmt.Errorf("%w: the blob must be at least %d bytes, but %d", ErrInvalidParameter, blockSize, len(blob))
+	}
+	message, want := blob[:len(blob)-blockSize], blob[len(blob)-blockSize:]
+	d, err := compressBytes(message)
+	if err != nil {
+		return nil, false, err
+	}
+	return message, subtle.ConstantTimeCompare(d[:], want) == 1, nil
+}
@@ -0,0 +1,68 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestAppendedTag(t *testing.T) {
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	message, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172a")
+	// CMAC of the message from RFC 4493
+	tag, _ := hex.DecodeString("070a16b46b4d4144f79bdd9dd04a287c")
+
+	blob, err := shecomp.CompressAppendTag(message, key)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if want := append(append([]byte{}, message...), tag...); !bytes.Equal(blob, want) {
+		t.Errorf("CompressAppendTag() = %x, want %x", blob, want)
+	}
+
+	got, ok, err := shecomp.VerifyAppendedTag(blob, key)
+	if err != nil || !ok {
+		t.Errorf("valid blob: got (%v, %v), want (true, nil)", ok, err)
+	}
+	if !bytes.Equal(got, message) {
+		t.Errorf("message = %x, want %x", got, message)
+	}
+
+	tampered := append([]byte{}, blob...)
+	tampered[0] ^= 0x01
+	if _, ok, err := shecomp.VerifyAppendedTag(tampered, key); err != nil || ok {
+		t.Errorf("tampered blob: got (%v, %v), want (false, nil)", ok, err)
+	}
+
+	if _, _, err := shecomp.VerifyAppendedTag(blob[:15], key); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for short blob, got %v", err)
+	}
+}
+
+func TestCompressSelfVerifying(t *testing.T) {
+	message, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	d := specDigest()
+	blob := append(bytes.Clone(message), d[:]...)
+
+	got, ok, err := shecomp.CompressSelfVerifying(bytes.NewReader(blob))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !ok || !bytes.Equal(got, message) {
+		t.Errorf("CompressSelfVerifying() = (%x, %v), want (%x, true)", got, ok, message)
+	}
+
+	blob[0] ^= 1
+	if _, ok, err := shecomp.CompressSelfVerifying(bytes.NewReader(blob)); err != nil || ok {
+		t.Errorf("tampered blob: CompressSelfVerifying() = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	if _, _, err := shecomp.CompressSelfVerifying(bytes.NewReader(d[:15])); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a short blob, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package shecomp
+
+import (
+	"fmt"
+	"io"
+)
+
+// The algorithm IDs of the tagged digests.
+const (
+	// AlgoSHE is the standard SHE compression with padding, same as Compress.
+	AlgoSHE byte = 0x01
+)
+
+const taggedSize = 1 + blockSize
+
+// CompressTagged compresses the input data with the algorithm of algoID, and returns the raw bytes
+// [algoID][16 bytes digest], so that a stored digest tells which algorithm produced it.
+// Only AlgoSHE is defined; otherwise it returns ErrInvalidParameter.
+func CompressTagged(r io.Reader, algoID byte) ([]byte, error) {
+	if err := validAlgo(algoID); err != nil {
+		return nil, err
+	}
+	d, err := CompressRaw(r)
+	if err != nil {
+		return nil, err
+	}
+	tagged := make([]byte, 0, taggedSize)
+	tagged = append(tagged, algoID)
+	return append(tagged, d...), nil
+}
+
+// ParseTagged splits the tagged digest created by CompressTagged into the algorithm ID and the raw digest.
+// It returns ErrInvalidParameter if the length is wrong or the algorithm ID is not defined.
+func ParseTagged(tagged []byte) (algoID byte, digest [blockSize]byte, err error) {
+	if len(tagged) != taggedSize {
+		return 0, digest, fmt.Errorf("%w: the length of the tagged digest must be %d bytes, but %d", ErrInvalidParameter, taggedSize, len(tagged))
+	}
+	if err := validAlgo(tagged[0]); err != nil {
+		return 0, digest, err
+	}
+	copy(digest[:], tagged[1:])
+	return tagged[0], digest, nil
+}
+
+func validAlgo(algoID byte) error {
+	if algoID != AlgoSHE {
+		return fmt.Errorf("%w: unknown algorithm ID %#02x", ErrInvalidParameter, algoID)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package shecomp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressTagged(t *testing.T) {
+	tagged, err := shecomp.CompressTagged(strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"), shecomp.AlgoSHE)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if len(tagged) != 17 || tagged[0] != 0x01 {
+		t.Errorf("CompressTagged() = %x", tagged)
+	}
+
+	algo, digest, err := shecomp.ParseTagged(tagged)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if algo != shecomp.AlgoSHE || digest != specDigest() {
+		t.Errorf("ParseTagged() = (%#02x, %x), want (%#02x, %x)", algo, digest, shecomp.AlgoSHE, specDigest())
+	}
+
+	if _, err := shecomp.CompressTagged(strings.NewReader(""), 0x02); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for an unknown algorithm, got %v", err)
+	}
+	tagged[0] = 0x02
+	if _, _, err := shecomp.ParseTagged(tagged); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for an unknown algorithm, got %v", err)
+	}
+	if _, _, err := shecomp.ParseTagged(tagged[:16]); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter for a short input, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package shecomp
+
+import "fmt"
+
+// CompressText compresses the bytes of the text with padding and returns the raw digest.
+// Unlike Compress, the text is not hexadecimal encoded: each byte of the string (UTF-8) is a message byte.
+func CompressText(s string) ([blockSize]byte, error) {
+	return compressBytes([]byte(s))
+}
+
+// CompressFormat renders fmt.Sprintf(format, args...) and compresses the rendered text same as CompressText.
+// It is useful to derive a digest from a structured identifier like "ECU-%d-KEY-%d".
+func CompressFormat(format string, args ...any) ([blockSize]byte, error) {
+	return CompressText(fmt.Sprintf(format, args...))
+}
+
+// CompressBoth compresses the raw data and its byte-reversed copy with padding, and returns both raw digests.
+// When a device disagrees with the forward digest but agrees with the reversed one,
+// the mismatch is likely caused by the byte order of the message.
+func CompressBoth(data []byte) (forward, reversed [blockSize]byte, err error) {
+	forward, err = compressBytes(data)
+	if err != nil {
+		return forward, reversed, err
+	}
+	rev := make([]byte, len(data))
+	for i, b := range data {
+		rev[len(data)-1-i] = b
+	}
+	reversed, err = compressBytes(rev)
+	return forward, reversed, err
+}
+
+// CompressXOR compresses the byte-wise XOR of the raw inputs a and b with padding, and returns the raw digest.
+// a and b must have the same length; otherwise it returns ErrInvalidParameter.
+func CompressXOR(a, b []byte) ([blockSize]byte, error) {
+	if len(a) != len(b) {
+		return [blockSize]byte{}, fmt.Errorf("%w: the lengths of the inputs must be same, but %d and %d", ErrInvalidParameter, len(a), len(b))
+	}
+	x := make([]byte, len(a))
+	for i := range x {
+		x[i] = a[i] ^ b[i]
+	}
+	return compressBytes(x)
+}
+
+// compressBytes compresses the raw message bytes with padding and returns the raw digest.
+func compressBytes(b []byte) ([blockSize]byte, error) {
+	var c Compressor
+	var digest [blockSize]byte
+	if _, err := c.Write(b); err != nil {
+		return digest, err
+	}
+	copy(digest[:], c.Sum(nil))
+	return digest, nil
+}
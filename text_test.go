@@ -0,0 +1,91 @@
+package shecomp_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressText(t *testing.T) {
+	s := "ECU-42-KEY-4"
+	got, err := shecomp.CompressText(s)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want, err := shecomp.Compress(strings.NewReader(hex.EncodeToString([]byte(s))))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if hex.EncodeToString(got[:]) != string(want) {
+		t.Errorf("CompressText() = %x, want %s", got, want)
+	}
+}
+
+func TestCompressFormat(t *testing.T) {
+	got, err := shecomp.CompressFormat("ECU-%d-KEY-%d", 42, 4)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want, err := shecomp.CompressText("ECU-42-KEY-4")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if got != want {
+		t.Errorf("CompressFormat() = %x, want %x", got, want)
+	}
+}
+
+func TestCompressBoth(t *testing.T) {
+	forward, reversed, err := shecomp.CompressBoth([]byte("ECU-42-24-UCE"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if forward != reversed {
+		t.Errorf("digests of a palindrome differ: %x and %x", forward, reversed)
+	}
+
+	forward, reversed, err = shecomp.CompressBoth([]byte("ECU-42"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want, _ := shecomp.CompressText("24-UCE")
+	if reversed != want {
+		t.Errorf("reversed digest = %x, want %x", reversed, want)
+	}
+	if forward == reversed {
+		t.Error("digests of a non-palindrome must differ")
+	}
+}
+
+func TestCompressXOR(t *testing.T) {
+	msg, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	a := make([]byte, len(msg))
+	rand.Read(a)
+	b := make([]byte, len(msg))
+	for i := range b {
+		b[i] = a[i] ^ msg[i]
+	}
+
+	got, err := shecomp.CompressXOR(a, b)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if got != specDigest() {
+		t.Errorf("CompressXOR() = %x, want %x", got, specDigest())
+	}
+
+	if _, err := shecomp.CompressXOR(a, b[1:]); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package shecomp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrReadTimeout is returned by CompressReadTimeout when reading a block takes too long.
+var ErrReadTimeout = errors.New("timeout reading a block")
+
+// CompressReadTimeout is same as Compress, but returns ErrReadTimeout if reading any single block of the input
+// takes longer than per. Unlike a deadline of the whole compression, the deadline applies to each block,
+// so a source stalling in the middle of the message is detected regardless of the length of the message.
+//
+// If r has SetReadDeadline, e.g. *os.File of a pipe or net.Conn, the deadline is set on r and cleared at the end.
+// Otherwise r is read by a single goroutine for the compression. A blocking Read of r can not be interrupted:
+// after the timeout, the goroutine keeps waiting for the pending Read until r returns, discards its result and exits.
+// In either case, the position of r is unknown after ErrReadTimeout, so r must not be reused.
+func CompressReadTimeout(r io.Reader, per time.Duration) ([]byte, error) {
+	dr, ok := r.(deadlineReader)
+	if ok && dr.SetReadDeadline(time.Time{}) == nil {
+		defer dr.SetReadDeadline(time.Time{})
+	} else {
+		ar := newAsyncReader(r)
+		defer ar.stop()
+		dr = ar
+	}
+	br := newPaddingReader(dr)
+	br.decode = withTimeout(br.decode, per)
+	return new(Compressor).run(br)
+}
+
+// CompressDeadline is same as CompressContext with a context which times out after d,
+// and returns the error wrapping context.DeadlineExceeded if the whole compression takes longer than d.
+// Same as CompressContext, a single blocking read of r is not interrupted.
+func CompressDeadline(r io.Reader, d time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return CompressContext(ctx, r)
+}
+
+// deadlineReader is a reader whose reads fail with os.ErrDeadlineExceeded after the deadline.
+type deadlineReader interface {
+	io.Reader
+	SetReadDeadline(t time.Time) error
+}
+
+// withTimeout returns the decoder which sets the deadline of src to per after now, and decodes with decode.
+// src must be a deadlineReader.
+func withTimeout(decode decoder, per time.Duration) decoder {
+	return func(dst []byte, src io.Reader) (int, error) {
+		if err := src.(deadlineReader).SetReadDeadline(time.Now().Add(per)); err != nil {
+			return 0, err
+		}
+		n, err := decode.read(dst, src)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return 0, fmt.Errorf("%w: no block within %v", ErrReadTimeout, per)
+		}
+		return n, err
+	}
+}
+
+// asyncReader is the deadlineReader reading r in its own goroutine.
+// The goroutine reads into its own buffer, since a Read may outlive the call waiting for it.
+type asyncReader struct {
+	r        io.Reader
+	deadline time.Time
+	err      error // sticky, once a read is given up
+	req      chan int
+	res      chan asyncResult
+	done     chan struct{}
+}
+
+type asyncResult struct {
+	b   []byte
+	err error
+}
+
+func newAsyncReader(r io.Reader) *asyncReader {
+	a := &asyncReader{
+		r:    r,
+		req:  make(chan int),
+		res:  make(chan asyncResult),
+		done: make(chan struct{}),
+	}
+	go a.loop()
+	return a
+}
+
+func (a *asyncReader) loop() {
+	var buf []byte
+	for {
+		select {
+		case n := <-a.req:
+			if cap(buf) < n {
+				buf = make([]byte, n)
+			}
+			n, err := a.r.Read(buf[:n])
+			select {
+			case a.res <- asyncResult{buf[:n], err}:
+			case <-a.done:
+				return
+			}
+		case <-a.done:
+			return
+		}
+	}
+}
+
+func (a *asyncReader) SetReadDeadline(t time.Time) error {
+	a.deadline = t
+	return nil
+}
+
+func (a *asyncReader) Read(p []byte) (int, error) {
+	if a.err != nil {
+		return 0, a.err
+	}
+	a.req <- len(p)
+
+	var timeout <-chan time.Time
+	if !a.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(a.deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case res := <-a.res:
+		return copy(p, res.b), res.err
+	case <-timeout:
+		// the pending read is discarded, so the following reads can not continue the stream
+		a.err = os.ErrDeadlineExceeded
+		return 0, a.err
+	}
+}
+
+// stop lets the goroutine exit once the pending Read of r, if any, returns.
+func (a *asyncReader) stop() {
+	close(a.done)
+}
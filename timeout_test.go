@@ -0,0 +1,136 @@
+package shecomp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+// stallReader returns the chunks in order, sleeping for the paired delay before each.
+type stallReader struct {
+	chunks []string
+	delays []time.Duration
+}
+
+func (r *stallReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delays[0])
+	n := copy(p, r.chunks[0])
+	r.chunks[0] = r.chunks[0][n:]
+	if len(r.chunks[0]) == 0 {
+		r.chunks, r.delays = r.chunks[1:], r.delays[1:]
+	}
+	return n, nil
+}
+
+func TestCompressReadTimeout(t *testing.T) {
+	first, second := "6bc1bee22e409f96e93d7e117393172a", "ae2d8a571e03ac9c9eb76fac45af8e51"
+
+	got, err := shecomp.CompressReadTimeout(&stallReader{
+		chunks: []string{first, second},
+		delays: []time.Duration{0, 0},
+	}, time.Second)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6"); !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressReadTimeout() = %s, want %s", got, want)
+	}
+
+	start := time.Now()
+	_, err = shecomp.CompressReadTimeout(&stallReader{
+		chunks: []string{first, second},
+		delays: []time.Duration{0, 500 * time.Millisecond},
+	}, 20*time.Millisecond)
+	if !errors.Is(err, shecomp.ErrReadTimeout) {
+		t.Errorf("expected ErrReadTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("CompressReadTimeout waited for the stalled read: %v", elapsed)
+	}
+}
+
+// hangReader returns the text, then blocks until release is closed.
+type hangReader struct {
+	s       string
+	release chan struct{}
+}
+
+func (r *hangReader) Read(p []byte) (int, error) {
+	if len(r.s) == 0 {
+		<-r.release
+		return 0, io.EOF
+	}
+	n := copy(p, r.s)
+	r.s = r.s[n:]
+	return n, nil
+}
+
+// waitGoroutines waits until the number of goroutines decreases to n.
+func waitGoroutines(t *testing.T, n int) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(10 * time.Millisecond) {
+		if runtime.NumGoroutine() <= n {
+			return
+		}
+	}
+	t.Errorf("%d goroutines are left, want %d", runtime.NumGoroutine(), n)
+}
+
+func TestCompressReadTimeoutGoroutines(t *testing.T) {
+	block := "6bc1bee22e409f96e93d7e117393172a"
+	base := runtime.NumGoroutine()
+
+	// the reader goroutine exits once the pending read returns
+	r := &hangReader{s: block, release: make(chan struct{})}
+	if _, err := shecomp.CompressReadTimeout(r, 20*time.Millisecond); !errors.Is(err, shecomp.ErrReadTimeout) {
+		t.Errorf("expected ErrReadTimeout, got %v", err)
+	}
+	close(r.release)
+	waitGoroutines(t, base)
+
+	// no goroutine is used for a reader with SetReadDeadline
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+	if _, err := io.WriteString(pw, block); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := shecomp.CompressReadTimeout(pr, 20*time.Millisecond); !errors.Is(err, shecomp.ErrReadTimeout) {
+		t.Errorf("expected ErrReadTimeout, got %v", err)
+	}
+	waitGoroutines(t, base)
+}
+
+func TestCompressDeadline(t *testing.T) {
+	block := "6bc1bee22e409f96e93d7e117393172a"
+	slow := &stallReader{}
+	for i := 0; i < 20; i++ {
+		slow.chunks = append(slow.chunks, block)
+		slow.delays = append(slow.delays, 20*time.Millisecond)
+	}
+	if _, err := shecomp.CompressDeadline(slow, 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	got, err := shecomp.CompressDeadline(&stallReader{
+		chunks: []string{block, "ae2d8a571e03ac9c9eb76fac45af8e51"},
+		delays: []time.Duration{0, 0},
+	}, time.Second)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if want := []byte("c7277a0dc1fb853b5f4d9cbd26be40c6"); !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressDeadline() = %s, want %s", got, want)
+	}
+}
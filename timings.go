@@ -0,0 +1,37 @@
+package shecomp
+
+import (
+	"io"
+	"time"
+)
+
+// Timings reports where the time of a compression is spent.
+type Timings struct {
+	// Decode is the time spent reading the input and decoding the hexadecimal text.
+	Decode time.Duration
+	// Compress is the time spent in the rest, which is mostly the padding and the Miyaguchi-Preneel steps.
+	Compress time.Duration
+}
+
+// CompressProfiled is same as Compress, but also returns the time spent decoding the input and compressing it.
+// The clock is read only twice per block to keep the measurement from skewing the result,
+// and Compress is derived as the total time minus Decode.
+// The timings are filled even if it returns an error.
+func CompressProfiled(r io.Reader) (digest []byte, timings Timings, err error) {
+	br := newPaddingReader(r)
+	decode := br.decode
+	br.decode = func(dst []byte, src io.Reader) (int, error) {
+		start := time.Now()
+		n, err := decode(dst, src)
+		timings.Decode += time.Since(start)
+		return n, err
+	}
+
+	start := time.Now()
+	digest, err = new(Compressor).run(br)
+	timings.Compress = time.Since(start) - timings.Decode
+	if timings.Compress < 0 {
+		timings.Compress = 0
+	}
+	return digest, timings, err
+}
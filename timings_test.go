@@ -0,0 +1,32 @@
+package shecomp_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressProfiled(t *testing.T) {
+	s := strings.Repeat("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", 1024)
+	want, _ := shecomp.Compress(strings.NewReader(s))
+
+	got, timings, err := shecomp.CompressProfiled(strings.NewReader(s))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("CompressProfiled() = %s, want %s", got, want)
+	}
+	if timings.Decode <= 0 || timings.Compress < 0 {
+		t.Errorf("unexpected timings: %+v", timings)
+	}
+
+	if _, timings, err := shecomp.CompressProfiled(strings.NewReader("012")); err == nil {
+		t.Error("expected error for invalid hexadecimal input")
+	} else if timings.Decode < 0 || timings.Compress < 0 {
+		t.Errorf("unexpected timings: %+v", timings)
+	}
+}
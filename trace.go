@@ -0,0 +1,53 @@
+package shecomp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type traceLine struct {
+	Index int    `json:"index"`
+	Input string `json:"input"`
+	State string `json:"state"`
+}
+
+type traceDigest struct {
+	Digest string `json:"digest"`
+}
+
+// CompressTraceJSONL compresses the input data same as Compress, and writes the trace into w in JSON lines.
+// Each block is written as soon as it is processed, as {"index":0,"input":"...","state":"..."},
+// where input is the block including the padding and state is the chaining state after the block, both in hexadecimal.
+// After all the blocks, it writes {"digest":"..."}.
+// If w has a Flush() error method like bufio.Writer, it is called after each line.
+func CompressTraceJSONL(w io.Writer, r io.Reader) error {
+	enc := json.NewEncoder(w)
+	emit := func(v any) error {
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to write the trace: %w", err)
+		}
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return fmt.Errorf("failed to flush the trace: %w", err)
+			}
+		}
+		return nil
+	}
+
+	c := &Compressor{
+		observe: func(index int, block, state []byte) error {
+			return emit(traceLine{
+				Index: index,
+				Input: hex.EncodeToString(block),
+				State: hex.EncodeToString(state),
+			})
+		},
+	}
+	out, err := c.CompressRaw(r)
+	if err != nil {
+		return err
+	}
+	return emit(traceDigest{Digest: hex.EncodeToString(out)})
+}
@@ -0,0 +1,45 @@
+package shecomp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestCompressTraceJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := shecomp.CompressTraceJSONL(w, strings.NewReader("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	// every line has been flushed without calling w.Flush
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	blocks := shecomp.EstimateBlocks(32)
+	if len(lines) != blocks+1 {
+		t.Errorf("got %d lines, want %d", len(lines), blocks+1)
+		return
+	}
+
+	for i, l := range lines[:blocks] {
+		var v struct {
+			Index int
+			Input string
+			State string
+		}
+		if err := json.Unmarshal([]byte(l), &v); err != nil {
+			t.Errorf("line %d: %v", i, err)
+			continue
+		}
+		if v.Index != i || len(v.Input) != 32 || len(v.State) != 32 {
+			t.Errorf("line %d: unexpected object %s", i, l)
+		}
+	}
+	if want := `{"digest":"c7277a0dc1fb853b5f4d9cbd26be40c6"}`; lines[blocks] != want {
+		t.Errorf("last line = %s, want %s", lines[blocks], want)
+	}
+}
@@ -0,0 +1,53 @@
+package shecomp
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTruncatedInput is returned when the input ends before the declared number of bytes arrives.
+var ErrTruncatedInput = errors.New("the input ended before the declared length")
+
+// TruncationAwareReader reads exactly the declared number of bytes from the underlying reader.
+// If the underlying reader reaches EOF before the declared length,
+// it returns ErrTruncatedInput instead of io.EOF, so that a truncated input
+// is distinguishable from a genuinely complete one.
+// After the declared length has been read, it returns io.EOF without reading further.
+type TruncationAwareReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// NewTruncationAwareReader returns a TruncationAwareReader which expects n bytes from r.
+func NewTruncationAwareReader(r io.Reader, n int64) *TruncationAwareReader {
+	return &TruncationAwareReader{
+		r:         r,
+		remaining: n,
+	}
+}
+
+// Read implements io.Reader.
+func (r *TruncationAwareReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if errors.Is(err, io.EOF) {
+		if r.remaining > 0 {
+			return n, ErrTruncatedInput
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return n, err
+}
+
+// Remaining returns the number of bytes which have not been read yet.
+func (r *TruncationAwareReader) Remaining() int64 {
+	return r.remaining
+}
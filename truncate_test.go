@@ -0,0 +1,66 @@
+package shecomp_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestTruncationAwareReader(t *testing.T) {
+	s := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+
+	tests := []struct {
+		name     string
+		input    string
+		declared int64
+		want     []byte
+		wantErr  error
+	}{
+		{
+			"complete frame",
+			s,
+			int64(len(s)),
+			[]byte("c7277a0dc1fb853b5f4d9cbd26be40c6"),
+			nil,
+		},
+		{
+			"trailing data beyond the declared length is not read",
+			s + "0000",
+			int64(len(s)),
+			[]byte("c7277a0dc1fb853b5f4d9cbd26be40c6"),
+			nil,
+		},
+		{
+			"frame ends early",
+			s[:40],
+			int64(len(s)),
+			nil,
+			shecomp.ErrTruncatedInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// DataErrReader reports io.EOF together with the last chunk, as many network readers do.
+			r := shecomp.NewTruncationAwareReader(iotest.DataErrReader(strings.NewReader(tt.input)), tt.declared)
+			got, err := shecomp.Compress(r)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("Compress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
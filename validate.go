@@ -0,0 +1,54 @@
+package shecomp
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidHex is returned by ValidateHex and the functions reading hexadecimal text, such as Compress and Padding,
+// when the input is not well-formed hexadecimal text.
+// The error also wraps hex.ErrLength or hex.InvalidByteError describing the problem.
+var ErrInvalidHex = errors.New("invalid hexadecimal input")
+
+// ValidateHex reads r to the end, and checks that every character is a hexadecimal digit
+// and the number of the characters is even, without running the compression.
+// It returns the length of the message in bytes after decoding.
+// It holds only a small fixed buffer, so it is cheap to reject a malformed input before compressing it.
+// If the message is too large for Compress, it returns ErrLargePlainText.
+func ValidateHex(r io.Reader) (byteLen uint64, err error) {
+	buf := make([]byte, 4096)
+	var chars uint64
+	for {
+		n, err := r.Read(buf)
+		for i, c := range buf[:n] {
+			if !isHexDigit(c) {
+				return 0, fmt.Errorf("%w at offset %d: %w", ErrInvalidHex, chars+uint64(i), hex.InvalidByteError(c))
+			}
+		}
+		chars += uint64(n)
+		if chars/2*8 > maxBitLength {
+			return 0, ErrLargePlainText
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not read from reader: %w", err)
+		}
+	}
+	if chars%2 != 0 {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidHex, hex.ErrLength)
+	}
+	return chars / 2, nil
+}
+
+// isSpace reports whether c is a whitespace skipped in the hexadecimal text: space, tab, CR or LF.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
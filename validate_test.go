@@ -0,0 +1,43 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestValidateHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    uint64
+		wantErr error
+	}{
+		{"valid", "6bc1bee22e409f96e93d7e117393172aAE2D8A571E03AC9C9EB76FAC45AF8E51", 32, nil},
+		{"empty", "", 0, nil},
+		{"odd length", "6bc", 0, hex.ErrLength},
+		{"invalid character", "6bc1zz", 0, hex.InvalidByteError('z')},
+		{"whitespace", "6bc1\n", 0, hex.InvalidByteError('\n')},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shecomp.ValidateHex(strings.NewReader(tt.input))
+			if tt.wantErr != nil {
+				if !errors.Is(err, shecomp.ErrInvalidHex) || !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected ErrInvalidHex and %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ValidateHex() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
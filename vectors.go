@@ -0,0 +1,73 @@
+package shecomp
+
+import (
+	"bytes"
+	"sync"
+)
+
+// VectorResult is the result of running a registered test vector.
+type VectorResult struct {
+	Name   string
+	Want   []byte // the expected raw digest
+	Got    []byte // the raw digest actually computed, nil if Err is not nil
+	Err    error
+	Passed bool
+}
+
+type vector struct {
+	name     string
+	input    []byte
+	expected []byte
+}
+
+var registry struct {
+	mu      sync.Mutex
+	vectors []vector
+}
+
+// RegisterVector registers a named test vector, which RunVectors executes.
+// input is the raw message to be compressed with padding, and expected is its raw digest.
+// Registering the same name again replaces the vector.
+// It is safe for concurrent use.
+func RegisterVector(name string, input, expected []byte) {
+	v := vector{
+		name:     name,
+		input:    bytes.Clone(input),
+		expected: bytes.Clone(expected),
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for i := range registry.vectors {
+		if registry.vectors[i].name == name {
+			registry.vectors[i] = v
+			return
+		}
+	}
+	registry.vectors = append(registry.vectors, v)
+}
+
+// RunVectors runs all registered test vectors in the order of registration and returns their results.
+// It is safe for concurrent use.
+func RunVectors() []VectorResult {
+	registry.mu.Lock()
+	vectors := append([]vector{}, registry.vectors...)
+	registry.mu.Unlock()
+
+	results := make([]VectorResult, 0, len(vectors))
+	for _, v := range vectors {
+		r := VectorResult{
+			Name: v.name,
+			Want: v.expected,
+		}
+		var c Compressor
+		if _, err := c.Write(v.input); err != nil {
+			r.Err = err
+		} else {
+			r.Got = c.Sum(nil)
+			r.Passed = bytes.Equal(r.Got, r.Want)
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,42 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestRunVectors(t *testing.T) {
+	input, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	digest, _ := hex.DecodeString("c7277a0dc1fb853b5f4d9cbd26be40c6")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		shecomp.RegisterVector("spec example", input, digest)
+	}()
+	go func() {
+		defer wg.Done()
+		shecomp.RegisterVector("deliberate failure", input, make([]byte, 16))
+	}()
+	wg.Wait()
+
+	results := map[string]shecomp.VectorResult{}
+	for _, r := range shecomp.RunVectors() {
+		results[r.Name] = r
+	}
+
+	if r, ok := results["spec example"]; !ok || !r.Passed || r.Err != nil {
+		t.Errorf("spec example: got %+v, want passed", r)
+	}
+	r, ok := results["deliberate failure"]
+	if !ok || r.Passed || r.Err != nil {
+		t.Errorf("deliberate failure: got %+v, want failed without error", r)
+	}
+	if hex.EncodeToString(r.Got) != hex.EncodeToString(digest) {
+		t.Errorf("deliberate failure: got digest %x, want %x", r.Got, digest)
+	}
+}
@@ -0,0 +1,131 @@
+package shecomp
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//go:embed vendorvectors.txt
+var vendorVectorsTxt string
+
+// NamedVector is a known answer vector of the compression bundled in the package.
+type NamedVector struct {
+	Name      string
+	Source    string // where the vector is published
+	NoPadding bool   // the message already contains the padding, as the input of CompressWithoutPadding
+	Message   []byte // the raw message
+	Digest    []byte // the raw expected digest
+}
+
+// VendorVectors returns the bundled known answer vectors in a fresh slice.
+// Only the vectors published with an explicit provenance are bundled,
+// which currently are the examples of SHE specification.
+func VendorVectors() []NamedVector {
+	vs, err := parseVendorVectors(vendorVectorsTxt)
+	if err != nil {
+		// unreachable: the embedded file is checked by the tests
+		panic(err)
+	}
+	return vs
+}
+
+// RunVendorVectors checks the implementation against all of VendorVectors,
+// and returns the joined errors of the failed vectors, or nil if all of them pass.
+// It is cheap enough to be called at startup.
+func RunVendorVectors() error {
+	return runNamedVectors(VendorVectors())
+}
+
+// SelfTest runs the known answer tests of VendorVectors same as RunVendorVectors,
+// and returns an error if any of them fails.
+func SelfTest() error {
+	vs := VendorVectors()
+	if brokenSelfTest {
+		for _, v := range vs {
+			v.Digest[0] ^= 0xff
+		}
+	}
+	if err := runNamedVectors(vs); err != nil {
+		return fmt.Errorf("self test failed: %w", err)
+	}
+	return nil
+}
+
+// MustPassSelfTest is same as SelfTest, but panics if it fails.
+// Calling it from an init function or at the start of main prevents a miscompiled binary
+// from producing wrong digests.
+func MustPassSelfTest() {
+	if err := SelfTest(); err != nil {
+		panic("shecomp: " + err.Error())
+	}
+}
+
+func runNamedVectors(vs []NamedVector) error {
+	var errs []error
+	for _, v := range vs {
+		var br BlockReader
+		if v.NoPadding {
+			br = &sliceReader{v.Message}
+		} else {
+			br = newPaddingReader(bytes.NewReader(v.Message))
+			br.(*paddingReader).decode = rawRead
+		}
+		got, err := compress(br)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("vector %s: %w", v.Name, err))
+			continue
+		}
+		if !bytes.Equal(got, v.Digest) {
+			errs = append(errs, fmt.Errorf("vector %s (%s): got %x, want %x", v.Name, v.Source, got, v.Digest))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseVendorVectors parses the vector file. The comment lines just before a vector are its source.
+func parseVendorVectors(txt string) ([]NamedVector, error) {
+	var vs []NamedVector
+	var comments []string
+	sc := bufio.NewScanner(strings.NewReader(txt))
+	for line := 1; sc.Scan(); line++ {
+		l := sc.Text()
+		switch {
+		case l == "":
+			comments = nil
+			continue
+		case strings.HasPrefix(l, "#"):
+			comments = append(comments, strings.TrimSpace(strings.TrimPrefix(l, "#")))
+			continue
+		}
+
+		f := strings.Split(l, "\t")
+		if len(f) != 4 || (f[1] != "pad" && f[1] != "nopad") {
+			return nil, fmt.Errorf("line %d: malformed vector %q", line, l)
+		}
+		msg, err := hex.DecodeString(f[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		digest, err := hex.DecodeString(f[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(comments) == 0 {
+			return nil, fmt.Errorf("line %d: vector %s has no provenance", line, f[0])
+		}
+		vs = append(vs, NamedVector{
+			Name:      f[0],
+			Source:    strings.Join(comments, " "),
+			NoPadding: f[1] == "nopad",
+			Message:   msg,
+			Digest:    digest,
+		})
+		comments = nil
+	}
+	return vs, sc.Err()
+}
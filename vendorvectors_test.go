@@ -0,0 +1,28 @@
+package shecomp_test
+
+import (
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestRunVendorVectors(t *testing.T) {
+	vs := shecomp.VendorVectors()
+	if len(vs) == 0 {
+		t.Error("no vector is bundled")
+	}
+	for _, v := range vs {
+		if v.Source == "" {
+			t.Errorf("vector %s has no provenance", v.Name)
+		}
+	}
+	if err := shecomp.RunVendorVectors(); err != nil {
+		t.Error(err)
+	}
+
+	// the returned slice must not alias the bundled data
+	vs[0].Digest[0] ^= 0xff
+	if err := shecomp.RunVendorVectors(); err != nil {
+		t.Errorf("modifying VendorVectors affected RunVendorVectors: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package shecomp
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// ErrVerifierState is returned when the methods of StreamVerifier are called out of order.
+var ErrVerifierState = errors.New("stream verifier used out of order")
+
+// StreamVerifier verifies a raw message streamed before its expected digest, without buffering the message.
+// The message is absorbed by Write, then the expected digest is given by Expected, and finally Valid reports the result.
+// Calling them in the other order returns ErrVerifierState.
+// The zero value is ready to use.
+type StreamVerifier struct {
+	c        Compressor
+	expected []byte
+}
+
+// NewStreamVerifier returns a new StreamVerifier.
+func NewStreamVerifier() *StreamVerifier {
+	return &StreamVerifier{}
+}
+
+// Write absorbs the raw message bytes. It returns ErrVerifierState after Expected is called.
+func (v *StreamVerifier) Write(p []byte) (int, error) {
+	if v.expected != nil {
+		return 0, fmt.Errorf("%w: Write after Expected", ErrVerifierState)
+	}
+	return v.c.Write(p)
+}
+
+// Expected sets the raw expected digest of the message written so far, which ends the message.
+// It returns ErrVerifierState if called twice, and ErrInvalidParameter if d is not 16 bytes.
+func (v *StreamVerifier) Expected(d []byte) error {
+	if v.expected != nil {
+		return fmt.Errorf("%w: Expected called twice", ErrVerifierState)
+	}
+	if len(d) != blockSize {
+		return fmt.Errorf("%w: the length of the digest must be %d bytes, but %d", ErrInvalidParameter, blockSize, len(d))
+	}
+	v.expected = make([]byte, blockSize)
+	copy(v.expected, d)
+	return nil
+}
+
+// Valid reports whether the digest of the message matches the expected one in constant time.
+// It returns ErrVerifierState before Expected is called.
+func (v *StreamVerifier) Valid() (bool, error) {
+	if v.expected == nil {
+		return false, fmt.Errorf("%w: Valid before Expected", ErrVerifierState)
+	}
+	return subtle.ConstantTimeCompare(v.c.Sum(nil), v.expected) == 1, nil
+}
@@ -0,0 +1,51 @@
+package shecomp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestStreamVerifier(t *testing.T) {
+	message, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	d := specDigest()
+
+	v := shecomp.NewStreamVerifier()
+	v.Write(message[:5])
+	v.Write(message[5:])
+	if err := v.Expected(d[:]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if ok, err := v.Valid(); !ok || err != nil {
+		t.Errorf("Valid() = (%v, %v), want (true, nil)", ok, err)
+	}
+
+	var tampered shecomp.StreamVerifier
+	tampered.Write(message[1:])
+	tampered.Expected(d[:])
+	if ok, err := tampered.Valid(); ok || err != nil {
+		t.Errorf("tampered: Valid() = (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestStreamVerifierOutOfOrder(t *testing.T) {
+	d := specDigest()
+
+	var v shecomp.StreamVerifier
+	if _, err := v.Valid(); !errors.Is(err, shecomp.ErrVerifierState) {
+		t.Errorf("Valid before Expected: expected ErrVerifierState, got %v", err)
+	}
+	if err := v.Expected(d[:8]); !errors.Is(err, shecomp.ErrInvalidParameter) {
+		t.Errorf("short digest: expected ErrInvalidParameter, got %v", err)
+	}
+	v.Expected(d[:])
+	if _, err := v.Write([]byte("late")); !errors.Is(err, shecomp.ErrVerifierState) {
+		t.Errorf("Write after Expected: expected ErrVerifierState, got %v", err)
+	}
+	if err := v.Expected(d[:]); !errors.Is(err, shecomp.ErrVerifierState) {
+		t.Errorf("Expected twice: expected ErrVerifierState, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package shecomp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithLengthWarning(t *testing.T) {
+	var fired []float64
+	c := NewCompressor(WithLengthWarning(0.9, func(used float64) {
+		fired = append(fired, used)
+	}))
+	// simulate a large input by the overridden limit of 1000 bytes
+	c.limit = 1000
+
+	if _, err := c.Compress(strings.NewReader(strings.Repeat("00", 800))); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(fired) != 0 {
+		t.Errorf("callback fired below the threshold: %v", fired)
+	}
+
+	if _, err := c.Compress(strings.NewReader(strings.Repeat("00", 960))); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// fired once at the first block beyond 900 bytes
+	if len(fired) != 1 || fired[0] != 0.912 {
+		t.Errorf("callback fired with %v, want [0.912]", fired)
+	}
+
+	fired = nil
+	if _, err := c.Compress(strings.NewReader(strings.Repeat("00", 1001))); !errors.Is(err, ErrLargePlainText) {
+		t.Errorf("expected ErrLargePlainText, got %v", err)
+	}
+	if len(fired) != 1 {
+		t.Errorf("callback fired %d times before ErrLargePlainText, want 1", len(fired))
+	}
+}
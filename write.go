@@ -0,0 +1,33 @@
+package shecomp
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// WriteCompress compresses the input data same as Compress, and writes the hexadecimal encoded digest to w
+// without allocating the output slice. It returns the number of bytes written, which is 32 on success.
+func WriteCompress(w io.Writer, r io.Reader) (int, error) {
+	return writeDigest(w, newPaddingReader(r))
+}
+
+// WriteCompressWithoutPadding is same as WriteCompress, but compresses the input same as CompressWithoutPadding.
+func WriteCompressWithoutPadding(w io.Writer, r io.Reader) (int, error) {
+	return writeDigest(w, &noPaddingReader{r: r, decode: newHexDecoder()})
+}
+
+func writeDigest(w io.Writer, br BlockReader) (int, error) {
+	d, err := NewCompressor().digest(context.Background(), br)
+	if err != nil {
+		return 0, err
+	}
+	var h [2 * blockSize]byte
+	hex.Encode(h[:], d)
+	n, err := w.Write(h[:])
+	if err != nil {
+		return n, fmt.Errorf("failed to write the digest: %w", err)
+	}
+	return n, nil
+}
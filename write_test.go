@@ -0,0 +1,45 @@
+package shecomp_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/tenkoh/go-shecomp"
+)
+
+func TestWriteCompress(t *testing.T) {
+	funcs := []struct {
+		name   string
+		write  func(io.Writer, io.Reader) (int, error)
+		reader func(io.Reader) ([]byte, error)
+	}{
+		{"WriteCompress", shecomp.WriteCompress, shecomp.Compress},
+		{"WriteCompressWithoutPadding", shecomp.WriteCompressWithoutPadding, shecomp.CompressWithoutPadding},
+	}
+	inputs := []string{
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+		"000102030405060708090a0b0c0d0e0f010153484500800000000000000000b0",
+	}
+
+	for _, f := range funcs {
+		for _, s := range inputs {
+			want, _ := f.reader(strings.NewReader(s))
+			var b bytes.Buffer
+			n, err := f.write(&b, strings.NewReader(s))
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", f.name, err)
+				continue
+			}
+			if n != 32 || b.String() != string(want) {
+				t.Errorf("%s() wrote %d bytes %s, want %s", f.name, n, b.String(), want)
+			}
+		}
+
+		var b bytes.Buffer
+		if _, err := f.write(&b, strings.NewReader("012")); err == nil || b.Len() != 0 {
+			t.Errorf("%s: expected error and no output for invalid input, got %v and %q", f.name, err, b.String())
+		}
+	}
+}